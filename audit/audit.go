@@ -0,0 +1,208 @@
+// Package audit ведет журнал действий операторов: дописываемый файл JSON строк,
+// в котором каждая запись содержит хеш предыдущей, поэтому правка, вставка или удаление записей
+// внутри журнала обнаруживается. Хеш без ключа: удаление последних записей или переписывание всей
+// цепочки обнаруживается только сверкой с вершиной (Head), сохраненной вне журнала.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// genesisHash - хеш "предыдущей" записи для первой записи журнала
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+// maxRecordSize - наибольший размер одной записи журнала
+const maxRecordSize = 1 << 20
+
+// Record - запись журнала: кто, когда и что запускал, с какими параметрами и результатом
+type Record struct {
+	Seq      int64             `json:"seq"`
+	Time     time.Time         `json:"time"`
+	Operator string            `json:"operator"`
+	Host     string            `json:"host"`
+	Action   string            `json:"action"`           // Команда: search, check, analyze, stix, decrypt...
+	Target   string            `json:"target,omitempty"` // Запрос, ссылка или входные файлы
+	Config   map[string]string `json:"config,omitempty"` // Явно заданные флаги
+	Findings map[string]int    `json:"findings,omitempty"`
+	Outputs  []string          `json:"outputs,omitempty"` // Созданные файлы и экспорты
+	Status   string            `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	PrevHash string            `json:"prev_hash"`
+	Hash     string            `json:"hash"`
+}
+
+// Head - вершина журнала: номер и хеш последней записи. Вершину сохраняют вне журнала
+// (в тикете, в другой системе), чтобы при проверке обнаружить удаление последних записей
+// и переписывание цепочки: журнал должен содержать запись с тем же номером и хешем.
+type Head struct {
+	Seq  int64
+	Hash string
+}
+
+// String возвращает вершину в виде <номер>:<хеш>
+func (h Head) String() string {
+	return fmt.Sprintf("%d:%s", h.Seq, h.Hash)
+}
+
+// ParseHead разбирает вершину в виде <номер>:<хеш>
+func ParseHead(s string) (Head, error) {
+	seqStr, hash, ok := strings.Cut(strings.TrimSpace(s), ":")
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if !ok || err != nil || seq < 1 {
+		return Head{}, fmt.Errorf("вершина журнала %q: ожидается <номер>:<хеш>", s)
+	}
+	if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+		return Head{}, fmt.Errorf("вершина журнала %q: хеш должен содержать %d hex символа", s, sha256.Size*2)
+	}
+	return Head{Seq: seq, Hash: strings.ToLower(hash)}, nil
+}
+
+// Append дописывает запись в журнал path, заполняя номер, хеш предыдущей записи и собственный хеш.
+// Файл создается с правами 0600; на время записи он блокируется от других процессов.
+func Append(path string, record Record) (Record, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return record, err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return record, fmt.Errorf("блокировка журнала: %w", err)
+	}
+	defer unlockFile(file)
+
+	last, err := lastRecord(file)
+	if err != nil {
+		return record, err
+	}
+
+	record.Seq = 1
+	record.PrevHash = genesisHash
+	if last != nil {
+		record.Seq = last.Seq + 1
+		record.PrevHash = last.Hash
+	}
+	record.Time = record.Time.UTC()
+	if record.Hash, err = hashRecord(record); err != nil {
+		return record, err
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return record, err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return record, err
+	}
+	return record, file.Sync()
+}
+
+// Verify проверяет цепочку хешей журнала и возвращает вершину последней корректной записи
+// (номер вершины равен числу корректных записей). При нарушении возвращается ошибка с номером
+// строки первой некорректной записи. Если задана сохраненная ранее вершина anchor, журнал должен
+// содержать запись с ее номером и хешем: иначе записи после нее удалены или цепочка переписана.
+func Verify(path string, anchor *Head) (Head, error) {
+	head := Head{Hash: genesisHash}
+	file, err := os.Open(path)
+	if err != nil {
+		return head, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return head, fmt.Errorf("строка %d: запись повреждена: %w", line, err)
+		}
+
+		if record.PrevHash != head.Hash {
+			return head, fmt.Errorf("строка %d: цепочка прервана, предыдущая запись изменена или удалена", line)
+		}
+		if record.Seq != head.Seq+1 {
+			return head, fmt.Errorf("строка %d: ожидался номер %d, в записи %d", line, head.Seq+1, record.Seq)
+		}
+		hash, err := hashRecord(record)
+		if err != nil {
+			return head, err
+		}
+		if record.Hash != hash {
+			return head, fmt.Errorf("строка %d: хеш не совпадает, запись изменена", line)
+		}
+		if anchor != nil && record.Seq == anchor.Seq && record.Hash != anchor.Hash {
+			return head, fmt.Errorf("строка %d: хеш не совпадает с сохраненной вершиной, цепочка переписана", line)
+		}
+
+		head = Head{Seq: record.Seq, Hash: record.Hash}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return head, err
+	}
+	if anchor != nil && head.Seq < anchor.Seq {
+		return head, fmt.Errorf("в журнале %d записей, сохраненная вершина - запись %d: последние записи удалены",
+			head.Seq, anchor.Seq)
+	}
+	return head, nil
+}
+
+// hashRecord вычисляет SHA-256 от хеша предыдущей записи и записи без собственного хеша
+func hashRecord(record Record) (string, error) {
+	record.Hash = ""
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(record.PrevHash))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// lastRecord читает последнюю запись журнала; для пустого журнала возвращается nil
+func lastRecord(file *os.File) (*Record, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+
+	chunk := int64(maxRecordSize + 1)
+	if chunk > size {
+		chunk = size
+	}
+	buf := make([]byte, chunk)
+	if _, err := file.ReadAt(buf, size-chunk); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	buf = bytes.TrimRight(buf, "\n")
+	if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+		buf = buf[i+1:]
+	}
+
+	var record Record
+	if err := json.Unmarshal(buf, &record); err != nil {
+		return nil, fmt.Errorf("последняя запись журнала повреждена: %w", err)
+	}
+	return &record, nil
+}
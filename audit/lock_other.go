@@ -0,0 +1,15 @@
+//go:build !unix
+
+package audit
+
+import "os"
+
+// lockFile на платформах без flock не блокирует файл: запись идет одним вызовом в режиме O_APPEND
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile ничего не делает на платформах без flock
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"telegraph-finder-go/parser"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+// Таймауты отправки во внешние системы
+const (
+	mispTimeout   = 30 * time.Second // Отправка события в MISP
+	syslogTimeout = 10 * time.Second // Подключение к приемнику syslog
+)
+
+// runAnalyze ищет аккаунты и вебхуки в статьях из сохраненного JSON экспорта поиска
+func runAnalyze(args []string) int {
+	fs := newFlagSet("analyze", "analyze [флаги] <results.json>",
+		"Загружает статьи из JSON экспорта команды search и ищет в них аккаунты, вебхуки, секреты по правилам\n"+
+			"и упоминания наших доменов и терминов.")
+	findOpts := addFindingFlags(fs)
+	findOpts.addWorkersFlag(fs)
+	findOpts.addMISPFlags(fs)
+	findOpts.addSyslogFlags(fs)
+	httpOpts := addHTTPFlags(fs)
+	summaryFlag := addSummaryFlag(fs)
+	metricsFlag := addMetricsFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if fs.NArg() != 1 {
+		return usageError(fs, "ожидается один файл с результатами поиска")
+	}
+	if err := findOpts.validate(); err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if err := httpOpts.validate(); err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if !findOpts.enabled() {
+		return usageError(fs, "укажите -accounts, -webhooks, -secret-rules и/или -brand")
+	}
+
+	summary := newSummary("analyze")
+
+	var results []parser.Article
+	if err := loadJSON(fs.Arg(0), &results); err != nil {
+		slog.Error("Ошибка при чтении результатов", "file", fs.Arg(0), "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+	summary.Counts.Articles = len(results)
+	summary.Counts.URLsProbed = int64(len(results))
+	if len(results) == 0 {
+		slog.Warn("Файл не содержит статей", "file", fs.Arg(0))
+		return summary.finish(exitOK, *summaryFlag)
+	}
+
+	config, err := httpOpts.config()
+	if err != nil {
+		slog.Error("Ошибка конфигурации", "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+	// Соединений и одновременных загрузок столько же, сколько обработчиков анализа
+	config.MaxConcurrentRequests = int64(findOpts.workers)
+	config.Metrics, err = startMetrics(*metricsFlag)
+	if err != nil {
+		slog.Error("Ошибка запуска метрик", "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failed, err := analyzeAndSave(ctx, config, parser.NewClient(config), results, findOpts, summary)
+	if err != nil {
+		slog.Error("Ошибка при анализе", "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+
+	// Статьи, которые не удалось загрузить, могли содержать находки
+	summary.ErrorRatio = float64(failed) / float64(len(results))
+	if failed == len(results) {
+		summary.fail(fmt.Errorf("не удалось загрузить ни одной статьи из %d", len(results)))
+		return summary.finish(exitError, *summaryFlag)
+	}
+	if failed > 0 {
+		return summary.finish(exitPartial, *summaryFlag)
+	}
+	if summary.findings(true) {
+		return summary.finish(exitFindings, *summaryFlag)
+	}
+	return summary.finish(exitOK, *summaryFlag)
+}
+
+// analyzeAndSave анализирует статьи, выводит и сохраняет найденные аккаунты и вебхуки,
+// счетчики, ошибки загрузки и созданные файлы добавляются в сводку.
+// Статьи загружаются клиентом client с настройками config, тем же, что и при поиске.
+// Возвращает число статей, которые не удалось проанализировать.
+func analyzeAndSave(ctx context.Context, config parser.ParserConfig, client *http.Client,
+	results []parser.Article, opts *findingOptions, summary *runSummary) (int, error) {
+	slog.Info("Начинаю анализ найденных статей", "articles", len(results), "workers", opts.workers)
+
+	metrics := config.Metrics
+	p, err := newFindingParser(config, opts, parser.WithClient(client))
+	if err != nil {
+		return 0, err
+	}
+
+	// Запускаем параллельный анализ результатов
+	startAnalyzeTime := time.Now()
+	errorReport := parser.NewErrorReport()
+	findings, err := parallelAnalyzeResults(ctx, p, results, opts.workers, errorReport)
+	if err != nil {
+		return 0, err
+	}
+	reportAnalyzeErrors(errorReport, len(results), opts.output+".analyze.errors", summary)
+
+	// Учитываем находки по типам в метриках
+	if metrics != nil {
+		for _, finding := range findings {
+			metrics.IncFinding(finding.Type)
+		}
+	}
+
+	if err := reportFindings(findings, opts, summary); err != nil {
+		return 0, err
+	}
+	if opts.misp.enabled() {
+		if err := exportMISP(ctx, results, findings, opts, summary); err != nil {
+			return 0, err
+		}
+	}
+	if opts.syslog.enabled() {
+		if err := sendSyslog(findings, opts.syslog); err != nil {
+			return 0, err
+		}
+	}
+
+	slog.Info("Анализ завершен", "duration", time.Since(startAnalyzeTime).Round(time.Second),
+		"accounts", summary.Counts.Accounts, "webhooks", summary.Counts.Webhooks, "secrets", summary.Counts.Secrets,
+		"mentions", summary.Counts.Mentions, "errors", errorReport.Total())
+	return errorReport.Total(), nil
+}
+
+// reportAnalyzeErrors выводит сводку ошибок загрузки статей, сохраняет их в filename и добавляет в сводку запуска
+func reportAnalyzeErrors(errorReport *parser.ErrorReport, articles int, filename string, summary *runSummary) {
+	if errorReport.Total() == 0 {
+		return
+	}
+	summary.addErrors(errorReport)
+
+	counts := errorReport.Counts()
+	var classes []any
+	for _, class := range errorReport.Classes() {
+		classes = append(classes, slog.Int(string(class), counts[class]))
+	}
+	slog.Warn("Часть статей не удалось проанализировать, результаты неполные", "errors", errorReport.Total(),
+		"articles", articles, slog.Group("classes", classes...), "file", filename)
+	if err := saveErrorsToFile(errorReport.Errors(), filename); err != nil {
+		slog.Error("Ошибка при сохранении журнала ошибок", "file", filename, "error", err)
+		return
+	}
+	summary.addOutput(filename, filename+".json")
+}
+
+// newFindingParser создает парсер с детекторами, включенными флагами, и списком наших доменов
+func newFindingParser(config parser.ParserConfig, opts *findingOptions, parserOpts ...parser.Option) (*parser.Parser, error) {
+	detectors, err := parser.DefaultRegistry().Select(opts.detectorNames()...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.secretRules != "" {
+		rules, err := parser.LoadSecretRules(opts.secretRules)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при загрузке правил секретов: %w", err)
+		}
+		detectors = append(detectors, rules)
+	}
+	watchlist, err := opts.watchlist.load()
+	if err != nil {
+		return nil, err
+	}
+	if opts.brand {
+		var terms []parser.BrandTerm
+		if opts.brandTerms != "" {
+			if terms, err = parser.LoadBrandTerms(opts.brandTerms); err != nil {
+				return nil, fmt.Errorf("ошибка при загрузке терминов: %w", err)
+			}
+		}
+		detectors = append(detectors, parser.NewBrandDetector(watchlist, terms))
+	}
+	key, err := loadFingerprintKey(opts.fingerprintKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки ключа отпечатков: %w", err)
+	}
+	parserOpts = append(parserOpts, parser.WithDetectors(detectors...), parser.WithWatchlist(watchlist),
+		parser.WithFingerprintKey(key))
+	if opts.includeServices() {
+		parserOpts = append(parserOpts, parser.WithServiceFindings())
+	}
+	return parser.New(config, parserOpts...), nil
+}
+
+// reportFindings выводит и сохраняет находки каждого включенного детектора в <o>.accounts, <o>.webhooks,
+// <o>.secrets и <o>.brand,
+// счетчики и созданные файлы добавляются в сводку
+func reportFindings(findings []parser.Finding, opts *findingOptions, summary *runSummary) error {
+	kinds := []struct {
+		enabled    bool
+		detector   string
+		typeFilter string
+		count      *int
+		found      string
+		notFound   string
+	}{
+		{opts.accounts, "accounts", opts.accountsType, &summary.Counts.Accounts, "аккаунтов", "Аккаунты не найдены"},
+		{opts.webhooks, "webhooks", opts.webhookType, &summary.Counts.Webhooks, "вебхуков", "Вебхуки не найдены"},
+		{opts.secretRules != "", "secrets", "all", &summary.Counts.Secrets, "секретов", "Секреты не найдены"},
+		{opts.brand, "brand", "all", &summary.Counts.Mentions, "упоминаний", "Упоминания не найдены"},
+	}
+
+	for _, kind := range kinds {
+		if !kind.enabled {
+			continue
+		}
+
+		selected := filterFindings(findingsByDetector(findings, kind.detector), kind.typeFilter)
+		if len(selected) == 0 {
+			fmt.Printf("\n%s\n", kind.notFound)
+			continue
+		}
+
+		fmt.Printf("\nНайдено %d %s:\n", len(selected), kind.found)
+		displayFindings(selected, kind.typeFilter)
+		filename := opts.output + "." + kind.detector
+		if err := saveFindingsToFile(selected, filename, kind.typeFilter); err != nil {
+			return fmt.Errorf("ошибка при сохранении %s: %w", filename, err)
+		}
+		*kind.count = len(selected)
+		summary.addOutput(filename, filename+".json")
+	}
+
+	return nil
+}
+
+// exportMISP сохраняет событие MISP в <o>.misp.json и, если задан -misp-url, отправляет его в MISP
+func exportMISP(ctx context.Context, results []parser.Article, findings []parser.Finding,
+	opts *findingOptions, summary *runSummary) error {
+	tlp := opts.misp.tlp
+	if tlp == "none" {
+		tlp = ""
+	}
+	event := parser.NewMISPEvent(results, findings, parser.MISPOptions{TLP: tlp, Now: time.Now()})
+
+	filename := opts.output + ".misp.json"
+	if err := writeJSONOutput(filename, event); err != nil {
+		return fmt.Errorf("ошибка при сохранении %s: %w", filename, err)
+	}
+	summary.addOutput(filename)
+
+	if opts.misp.url == "" {
+		return nil
+	}
+
+	client := &http.Client{Timeout: mispTimeout}
+	if err := parser.PostMISPEvent(ctx, client, opts.misp.url, os.Getenv(opts.misp.keyEnv), event); err != nil {
+		return fmt.Errorf("ошибка при отправке события в MISP: %w", err)
+	}
+	slog.Info("Событие отправлено в MISP", "url", opts.misp.url, "event", event.Event.UUID,
+		"attributes", len(event.Event.Attribute))
+	return nil
+}
+
+// sendSyslog отправляет в SIEM находки, которых нет в экспортах -syslog-baseline
+func sendSyslog(findings []parser.Finding, opts *syslogOptions) error {
+	known := make(map[string]bool)
+	if opts.baseline != "" {
+		for _, filename := range strings.Split(opts.baseline, ",") {
+			var baseline []parser.Finding
+			if err := loadJSON(strings.TrimSpace(filename), &baseline); err != nil {
+				return fmt.Errorf("ошибка при чтении -syslog-baseline: %w", err)
+			}
+			for _, finding := range baseline {
+				known[finding.Fingerprint] = true
+			}
+		}
+	}
+
+	sink, err := parser.DialSyslog(opts.network, opts.addr, syslogTimeout)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к syslog: %w", err)
+	}
+	defer sink.Close()
+
+	sent := 0
+	for _, finding := range findings {
+		if known[finding.Fingerprint] {
+			continue
+		}
+		known[finding.Fingerprint] = true
+		if err := sink.SendFinding(finding); err != nil {
+			return fmt.Errorf("ошибка отправки в syslog: %w", err)
+		}
+		sent++
+	}
+
+	slog.Info("Находки отправлены в syslog", "addr", opts.addr, "network", opts.network, "sent", sent)
+	return nil
+}
+
+// parallelAnalyzeResults параллельно запускает детекторы парсера на найденных статьях,
+// ошибки загрузки статей добавляются в errorReport
+func parallelAnalyzeResults(ctx context.Context, p *parser.Parser, results []parser.Article,
+	maxWorkers int, errorReport *parser.ErrorReport) ([]parser.Finding, error) {
+
+	var allFindings []parser.Finding
+	var findingsMu sync.Mutex
+
+	// Создаем семафор для ограничения количества параллельных запросов
+	sem := semaphore.NewWeighted(int64(maxWorkers))
+
+	// Создаем группу ошибок для синхронизации горутин
+	g, gctx := errgroup.WithContext(ctx)
+
+	// Счетчик прогресса анализа, останавливается после завершения всех горутин
+	tracker := parser.NewProgressTracker(int64(len(results)), 200*time.Millisecond, func(p parser.Progress) {
+		printProgress("Анализ статей", p)
+	})
+	defer tracker.Stop()
+
+	// Обрабатываем каждую статью параллельно
+	for _, article := range results {
+		url := article.URL
+
+		g.Go(func() error {
+			// Приобретаем семафор
+			if err := sem.Acquire(gctx, 1); err != nil {
+				return err
+			}
+			defer sem.Release(1)
+
+			findings, err := p.Analyze(gctx, url)
+			if err != nil {
+				slog.Debug("Ошибка при анализе статьи", "url", url, "error", err)
+				errorReport.Add(url, err)
+				tracker.AddError()
+			} else if len(findings) > 0 {
+				findingsMu.Lock()
+				allFindings = append(allFindings, findings...)
+				findingsMu.Unlock()
+				slog.Info("Найдены данные наших доменов", "count", len(findings), "url", url)
+				tracker.AddHit()
+			}
+			tracker.AddProbed()
+
+			return nil
+		})
+	}
+
+	// Ожидаем завершения всех горутин
+	err := g.Wait()
+
+	// Финальный снимок прогресса и новая строка после него
+	tracker.Stop()
+	fmt.Println()
+
+	return allFindings, err
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"telegraph-finder-go/audit"
+)
+
+// defaultAuditEnv - переменная окружения с путем журнала аудита по умолчанию
+const defaultAuditEnv = "TELEGRAPH_FINDER_AUDIT_LOG"
+
+// auditTrail - запись журнала аудита текущего запуска. Настраивается в parseFlags,
+// дополняется командой (сводка, экспорты) и записывается при завершении в main.
+var auditTrail struct {
+	path     string
+	recorded bool
+	record   audit.Record
+}
+
+// addAuditFlags регистрирует флаги журнала аудита
+func addAuditFlags(fs *flag.FlagSet) {
+	fs.String("audit-log", os.Getenv(defaultAuditEnv), "Журнал аудита действий операторов (JSON строки с цепочкой хешей), по умолчанию $"+defaultAuditEnv)
+	fs.String("operator", "", "Имя оператора для журнала аудита (по умолчанию - пользователь системы)")
+}
+
+// setupAudit запоминает оператора, команду, цель и явно заданные флаги для журнала аудита
+func setupAudit(fs *flag.FlagSet) {
+	auditTrail.path = fs.Lookup("audit-log").Value.String()
+	if auditTrail.path == "" {
+		return
+	}
+
+	config := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		config[f.Name] = f.Value.String()
+	})
+
+	host, _ := os.Hostname()
+	auditTrail.record = audit.Record{
+		Time:     time.Now(),
+		Operator: auditOperator(fs.Lookup("operator").Value.String()),
+		Host:     host,
+		Action:   fs.Name(),
+		Target:   strings.Join(fs.Args(), " "),
+		Config:   config,
+	}
+}
+
+// auditOperator возвращает имя оператора; имя из -operator дополняется пользователем системы
+func auditOperator(name string) string {
+	system := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		system = u.Username
+	}
+
+	switch {
+	case name == "":
+		return system
+	case system == "" || name == system:
+		return name
+	default:
+		return fmt.Sprintf("%s (%s)", name, system)
+	}
+}
+
+// auditOutput добавляет созданный файл или экспорт в запись аудита
+func auditOutput(files ...string) {
+	auditTrail.record.Outputs = append(auditTrail.record.Outputs, files...)
+}
+
+// recordAudit дописывает запись текущего запуска в журнал аудита. Повторные вызовы ничего не делают.
+func recordAudit(exitCode int, failure string) error {
+	if auditTrail.path == "" || auditTrail.recorded {
+		return nil
+	}
+	auditTrail.recorded = true
+
+	record := auditTrail.record
+	record.Status = exitStatus(exitCode)
+	record.Error = failure
+	if _, err := audit.Append(auditTrail.path, record); err != nil {
+		return fmt.Errorf("ошибка записи журнала аудита %s: %w", auditTrail.path, err)
+	}
+	return nil
+}
+
+// runAuditVerify проверяет цепочку хешей журнала аудита
+func runAuditVerify(args []string) int {
+	fs := newFlagSet("audit-verify", "audit-verify [флаги] <журнал>",
+		"Проверяет журнал аудита: номера записей и цепочку хешей. Изменение, удаление\n"+
+			"или вставка записей внутри журнала обнаруживается по первой некорректной строке.\n"+
+			"Хеши без ключа: удаление последних записей, журнала целиком или переписывание всей цепочки\n"+
+			"обнаруживается только сверкой с вершиной, сохраненной вне журнала после прошлой проверки (-expect-head).")
+	expectHead := fs.String("expect-head", "", "Вершина журнала из прошлой проверки (<номер>:<хеш>): журнал должен ее содержать")
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+	// Проверка журнала сама в журнал не записывается
+	auditTrail.path = ""
+	if fs.NArg() != 1 {
+		return usageError(fs, "ожидается один файл журнала")
+	}
+	var anchor *audit.Head
+	if *expectHead != "" {
+		head, err := audit.ParseHead(*expectHead)
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+		anchor = &head
+	}
+
+	head, err := audit.Verify(fs.Arg(0), anchor)
+	if err != nil {
+		fmt.Printf("Журнал нарушен после %d корректных записей: %v\n", head.Seq, err)
+		return exitError
+	}
+	fmt.Printf("Журнал корректен, записей: %d\n", head.Seq)
+	fmt.Printf("Вершина (сохраните вне журнала для -expect-head): %s\n", head)
+	return exitOK
+}
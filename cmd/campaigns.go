@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"telegraph-finder-go/parser"
+)
+
+// runCampaigns группирует статьи из JSON экспорта поиска в кампании перепубликаций
+func runCampaigns(args []string) int {
+	fs := newFlagSet("campaigns", "campaigns [флаги] <results.json>",
+		"Группирует статьи из JSON экспорта команды search в кампании: перепубликации одного текста\n"+
+			"под разными ссылками определяются по SimHash и выводятся одной записью со всеми зеркалами.")
+	distanceFlag := fs.Int("distance", parser.DefaultCampaignDistance, "Наибольшее расстояние Хэмминга между SimHash зеркал (0-64)")
+	minMirrorsFlag := fs.Int("min-mirrors", 1, "Выводить кампании не менее чем с указанным числом зеркал")
+	outputFlag := fs.String("o", "", "Файл для сохранения кампаний (и <файл>.json)")
+	jsonFlag := fs.Bool("json", false, "Вывести кампании в JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if fs.NArg() != 1 {
+		return usageError(fs, "ожидается один файл с результатами поиска")
+	}
+	if *distanceFlag < 0 || *distanceFlag > 64 {
+		return usageError(fs, "-distance должен быть от 0 до 64")
+	}
+	if *minMirrorsFlag < 1 {
+		return usageError(fs, "-min-mirrors должен быть больше 0")
+	}
+
+	var articles []parser.Article
+	if err := loadJSON(fs.Arg(0), &articles); err != nil {
+		slog.Error("Ошибка при чтении результатов", "file", fs.Arg(0), "error", err)
+		return exitError
+	}
+
+	campaigns := filterCampaigns(parser.ClusterCampaigns(articles, *distanceFlag), *minMirrorsFlag)
+
+	if *outputFlag != "" {
+		if err := saveCampaignsToFile(campaigns, *outputFlag); err != nil {
+			slog.Error("Ошибка при сохранении кампаний", "file", *outputFlag, "error", err)
+			return exitError
+		}
+		auditOutput(*outputFlag, *outputFlag+".json")
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(campaigns); err != nil {
+			slog.Error("Ошибка при выводе JSON", "error", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	fmt.Printf("Кампаний: %d (статей: %d)\n", len(campaigns), len(parser.SortArticles(articles)))
+	displayCampaigns(campaigns)
+	return exitOK
+}
+
+// filterCampaigns оставляет кампании с числом зеркал не меньше minMirrors
+func filterCampaigns(campaigns []parser.Campaign, minMirrors int) []parser.Campaign {
+	var filtered []parser.Campaign
+	for _, campaign := range campaigns {
+		if len(campaign.Articles) >= minMirrors {
+			filtered = append(filtered, campaign)
+		}
+	}
+	return filtered
+}
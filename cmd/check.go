@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"telegraph-finder-go/parser"
+)
+
+// runCheck проверяет одну ссылку: доступность статьи, аккаунты и/или вебхуки
+func runCheck(args []string) int {
+	fs := newFlagSet("check", "check [флаги] <ссылка>",
+		"Проверяет ссылку на Telegraph. С -accounts, -webhooks, -secret-rules и -brand выполняются все указанные проверки,\n"+
+			"находки доменов из -watchlist сохраняются в <o>.accounts, <o>.webhooks, <o>.secrets и <o>.brand.")
+	findOpts := addFindingFlags(fs)
+	httpOpts := addHTTPFlags(fs)
+	summaryFlag := addSummaryFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if fs.NArg() != 1 {
+		return usageError(fs, "ожидается одна ссылка")
+	}
+	if err := findOpts.validate(); err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if err := httpOpts.validate(); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	link := fs.Arg(0)
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return usageError(fs, "некорректная ссылка %q", link)
+	}
+
+	summary := newSummary("check")
+	summary.URL = link
+	summary.Counts.URLsProbed = 1
+
+	config, err := httpOpts.config()
+	if err != nil {
+		slog.Error("Ошибка конфигурации", "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+
+	// Создаем контекст с возможностью отмены
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Обычная проверка ссылки
+	if !findOpts.enabled() {
+		p := parser.New(config)
+		slog.Info("Проверка ссылки", "url", link)
+		result, err := p.FindArticle(ctx, link)
+		if err != nil {
+			slog.Error("Ошибка при проверке ссылки", "url", link, "error", err)
+			return summary.finishError(err, *summaryFlag)
+		}
+
+		if result != "" {
+			fmt.Println("Ссылка доступна и содержит контент:")
+			fmt.Println(result)
+			summary.Counts.Articles = 1
+			return summary.finish(exitFindings, *summaryFlag)
+		}
+		fmt.Println("Ссылка недоступна или содержит недопустимый контент")
+		return summary.finish(exitOK, *summaryFlag)
+	}
+
+	// Проверка ссылки детекторами аккаунтов и/или вебхуков
+	p, err := newFindingParser(config, findOpts)
+	if err != nil {
+		slog.Error("Ошибка при настройке детекторов", "error", err)
+		return summary.finishError(err, *summaryFlag)
+	}
+
+	slog.Info("Поиск аккаунтов и вебхуков", "url", link, "detectors", findOpts.detectorNames())
+	findings, err := p.Analyze(ctx, link)
+	if err != nil {
+		slog.Error("Ошибка при проверке ссылки", "url", link, "error", err)
+		return summary.finishError(err, *summaryFlag)
+	}
+	if err := reportFindings(findings, findOpts, summary); err != nil {
+		slog.Error("Ошибка при сохранении находок", "error", err)
+		return summary.finishError(err, *summaryFlag)
+	}
+
+	if summary.findings(true) {
+		return summary.finish(exitFindings, *summaryFlag)
+	}
+	return summary.finish(exitOK, *summaryFlag)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"telegraph-finder-go/parser"
+)
+
+// runDecrypt расшифровывает выходной файл для чтения или передачи
+func runDecrypt(args []string) int {
+	fs := newFlagSet("decrypt", "decrypt [флаги] <файл>",
+		"Расшифровывает файл, сохраненный с ключом (-key-file или "+defaultKeyEnv+"), и выводит его содержимое.\n"+
+			"С -o содержимое сохраняется в открытом виде в файл с правами 0600 для передачи.")
+	outputFlag := fs.String("o", "", "Файл для открытой копии (по умолчанию - стандартный вывод)")
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if fs.NArg() != 1 {
+		return usageError(fs, "ожидается один файл")
+	}
+	if outputSealer == nil {
+		return usageError(fs, "не задан ключ: -key-file или %s", defaultKeyEnv)
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		slog.Error("Ошибка при чтении файла", "file", fs.Arg(0), "error", err)
+		return exitError
+	}
+	if parser.IsSealed(data) {
+		if data, err = outputSealer.Open(data); err != nil {
+			slog.Error("Ошибка при расшифровке", "file", fs.Arg(0), "error", err)
+			return exitError
+		}
+	} else {
+		slog.Warn("Файл не зашифрован", "file", fs.Arg(0))
+	}
+
+	if *outputFlag == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			slog.Error("Ошибка при выводе", "error", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	// Открытая копия сохраняется без шифрования, но только для владельца
+	file, err := os.OpenFile(*outputFlag, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		slog.Error("Ошибка при создании файла", "file", *outputFlag, "error", err)
+		return exitError
+	}
+	_, writeErr := file.Write(data)
+	if closeErr := file.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		slog.Error("Ошибка при сохранении файла", "file", *outputFlag, "error", writeErr)
+		return exitError
+	}
+
+	auditOutput(*outputFlag)
+	slog.Info("Открытая копия сохранена", "file", *outputFlag)
+	return exitOK
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"telegraph-finder-go/parser"
+)
+
+// runDiff сравнивает два JSON экспорта и выводит разницу, возвращает код выхода
+func runDiff(args []string) int {
+	fs := newFlagSet("diff", "diff [флаги] <старый.json> <новый.json>",
+		"Сравнивает два JSON экспорта: новые, исчезнувшие и измененные страницы (articles)\n"+
+			"или новые находки по доменам со скрытыми секретами (accounts, webhooks, secrets, brand), сравнивая отпечатки находок.\n"+
+			"Отпечатки совпадают только у экспортов, созданных с одним ключом отпечатков (-fingerprint-key-file).")
+	kindFlag := fs.String("kind", "articles", "Тип экспорта: articles, accounts, webhooks, secrets, brand")
+	watchlistOpts := addWatchlistFlags(fs)
+	jsonFlag := fs.Bool("json", false, "Вывести разницу в JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if fs.NArg() != 2 {
+		return usageError(fs, "ожидается два файла для сравнения")
+	}
+	oldFile, newFile := fs.Arg(0), fs.Arg(1)
+
+	watchlist, err := watchlistOpts.load()
+	if err != nil {
+		slog.Error("Ошибка при загрузке списка доменов", "error", err)
+		return exitError
+	}
+
+	var result interface{}
+	switch *kindFlag {
+	case "articles":
+		var oldArticles, newArticles []parser.Article
+		if err := loadJSONPair(oldFile, newFile, &oldArticles, &newArticles); err != nil {
+			slog.Error("Ошибка при чтении экспортов", "error", err)
+			return exitError
+		}
+		diff := parser.DiffArticles(oldArticles, newArticles)
+		if !*jsonFlag {
+			displayArticleDiff(diff)
+		}
+		result = diff
+	case "accounts", "webhooks", "secrets", "brand":
+		var oldFindings, newFindings []parser.Finding
+		if err := loadJSONPair(oldFile, newFile, &oldFindings, &newFindings); err != nil {
+			slog.Error("Ошибка при чтении экспортов", "error", err)
+			return exitError
+		}
+		diff := parser.DiffFindings(oldFindings, newFindings, watchlist)
+		if !*jsonFlag {
+			displayFindingsDiff(diff)
+		}
+		result = diff
+	default:
+		return usageError(fs, "неизвестный тип экспорта %q", *kindFlag)
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			slog.Error("Ошибка при выводе JSON", "error", err)
+			return exitError
+		}
+	}
+
+	return exitOK
+}
+
+// loadJSONPair читает старый и новый экспорты
+func loadJSONPair(oldFile, newFile string, oldData, newData interface{}) error {
+	if err := loadJSON(oldFile, oldData); err != nil {
+		return err
+	}
+	return loadJSON(newFile, newData)
+}
+
+// loadJSON читает JSON экспорт из файла
+func loadJSON(filename string, data interface{}) error {
+	content, err := readInput(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(content, data); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
+
+// displayArticleDiff выводит новые, исчезнувшие и измененные страницы
+func displayArticleDiff(diff parser.ArticleDiff) {
+	fmt.Printf("Новые страницы: %d\n", len(diff.Added))
+	for i, article := range diff.Added {
+		fmt.Printf("  %d. %s\n", i+1, article)
+	}
+
+	fmt.Printf("\nИсчезнувшие страницы: %d\n", len(diff.Removed))
+	for i, article := range diff.Removed {
+		fmt.Printf("  %d. %s\n", i+1, article)
+	}
+
+	fmt.Printf("\nИзмененное содержимое: %d\n", len(diff.Changed))
+	for i, change := range diff.Changed {
+		fmt.Printf("  %d. %s (%.12s -> %.12s)\n", i+1, change.New, change.Old.ContentHash, change.New.ContentHash)
+	}
+}
+
+// displayFindingsDiff выводит новые находки по доменам
+func displayFindingsDiff(diff parser.FindingsDiff) {
+	fmt.Printf("Новые находки: %d\n", diff.Total)
+	for _, domain := range diff.Domains() {
+		name := domain
+		if name == "" {
+			name = "(без домена)"
+		}
+
+		findings := diff.ByDomain[domain]
+		fmt.Printf("\n%s: %d\n", name, len(findings))
+		for i, finding := range findings {
+			fmt.Printf("  %d. [%s] %s (%s)\n", i+1, finding.Type, finding.Value, finding.Source)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"telegraph-finder-go/parser"
+)
+
+// defaultKeyEnv - переменная окружения с ключом шифрования по умолчанию
+const defaultKeyEnv = "TELEGRAPH_FINDER_KEY"
+
+// fingerprintKeyEnv - переменная окружения с ключом отпечатков находок
+const fingerprintKeyEnv = "TELEGRAPH_FINDER_FINGERPRINT_KEY"
+
+// outputSealer шифрует выходные файлы и кеш; nil - ключ не задан, файлы сохраняются открытыми.
+// Настраивается в parseFlags вместе с журналом.
+var outputSealer *parser.Sealer
+
+// addKeyFlags регистрирует флаги ключа шифрования выходных файлов
+func addKeyFlags(fs *flag.FlagSet) {
+	fs.String("key-file", "", "Файл с ключом AES-256 (hex или base64) для шифрования выходных файлов и кеша")
+	fs.String("key-env", defaultKeyEnv, "Переменная окружения с ключом, если -key-file не указан")
+}
+
+// loadKey загружает ключ шифрования из флагов подкоманды
+func loadKey(fs *flag.FlagSet) error {
+	sealer, err := parser.LoadSealer(fs.Lookup("key-file").Value.String(), fs.Lookup("key-env").Value.String())
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки ключа шифрования: %w", err)
+	}
+	outputSealer = sealer
+	return nil
+}
+
+// loadFingerprintKey загружает ключ HMAC для отпечатков находок из файла, из fingerprintKeyEnv или
+// из каталога настроек пользователя. Ключ в каталоге настроек создается при первом запуске, чтобы
+// отпечатки совпадали между запусками (diff, -syslog-baseline) без настройки.
+func loadFingerprintKey(keyFile string) ([]byte, error) {
+	if keyFile == "" {
+		if key := strings.TrimSpace(os.Getenv(fingerprintKeyEnv)); key != "" {
+			return checkFingerprintKey(fingerprintKeyEnv, key)
+		}
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("укажите -fingerprint-key-file или %s: %w", fingerprintKeyEnv, err)
+		}
+		keyFile = filepath.Join(dir, "telegraph-finder", "fingerprint.key")
+		if err := createFingerprintKey(keyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return checkFingerprintKey(keyFile, strings.TrimSpace(string(data)))
+}
+
+// checkFingerprintKey проверяет длину ключа отпечатков из источника source
+func checkFingerprintKey(source, key string) ([]byte, error) {
+	if len(key) < parser.MinFingerprintKey {
+		return nil, fmt.Errorf("%s: ключ отпечатков короче %d байт", source, parser.MinFingerprintKey)
+	}
+	return []byte(key), nil
+}
+
+// createFingerprintKey создает файл со случайным ключом отпечатков, если его еще нет
+func createFingerprintKey(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	_, writeErr := file.WriteString(hex.EncodeToString(key) + "\n")
+	if err := file.Close(); writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		os.Remove(filename)
+	}
+	return writeErr
+}
+
+// writeOutput атомарно сохраняет данные в файл с правами 0600, зашифровав их, если задан ключ
+func writeOutput(filename string, data []byte) error {
+	if outputSealer != nil {
+		sealed, err := outputSealer.Seal(data)
+		if err != nil {
+			return err
+		}
+		data = sealed
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".output-*")
+	if err != nil {
+		return err
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		if writeErr != nil {
+			return writeErr
+		}
+		return closeErr
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// writeJSONOutput сохраняет значение в JSON с отступами через writeOutput
+func writeJSONOutput(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(filename, append(data, '\n'))
+}
+
+// readInput читает файл, созданный writeOutput, расшифровывая его при необходимости
+func readInput(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !parser.IsSealed(data) {
+		return data, nil
+	}
+	if outputSealer == nil {
+		return nil, fmt.Errorf("%s: %w: -key-file или %s", filename, parser.ErrSealed, defaultKeyEnv)
+	}
+
+	data, err = outputSealer.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return data, nil
+}
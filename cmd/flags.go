@@ -0,0 +1,409 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"telegraph-finder-go/parser"
+)
+
+// Допустимые значения фильтров по типу находок
+var (
+	accountTypes = []string{"all", "email", "minecraft", "account", "login", "username", "user"}
+	webhookTypes = []string{"all", "discord", "github", "slack", "generic"}
+)
+
+// newFlagSet создает набор флагов подкоманды со справкой
+func newFlagSet(cmd, usage, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Использование:")
+		fmt.Printf("  telegraph-finder %s\n\n", usage)
+		fmt.Println(description)
+		fmt.Println("\nФлаги:")
+		fs.PrintDefaults()
+		printExitCodes()
+	}
+	addLogFlags(fs)
+	addKeyFlags(fs)
+	addAuditFlags(fs)
+	return fs
+}
+
+// usageError выводит ошибку и справку по подкоманде
+func usageError(fs *flag.FlagSet, format string, args ...interface{}) int {
+	fmt.Printf("Ошибка: "+format+"\n\n", args...)
+	fs.Usage()
+	return exitUsage
+}
+
+// httpOptions - параметры HTTP клиента, общие для search, check и analyze
+type httpOptions struct {
+	timeout     int
+	cacheDir    string
+	negativeTTL time.Duration
+	maxBodyKB   int64
+	userAgent   string
+}
+
+// addHTTPFlags регистрирует флаги HTTP клиента
+func addHTTPFlags(fs *flag.FlagSet) *httpOptions {
+	o := &httpOptions{}
+	fs.IntVar(&o.timeout, "timeout", 10, "Таймаут HTTP запросов в секундах")
+	fs.StringVar(&o.cacheDir, "cache-dir", "", "Каталог HTTP кеша для повторных запусков (условные запросы по ETag/Last-Modified)")
+	fs.DurationVar(&o.negativeTTL, "negative-ttl", 24*time.Hour, "Сколько не перепроверять ссылки, вернувшие 404 (с -cache-dir)")
+	fs.Int64Var(&o.maxBodyKB, "max-body-kb", parser.DefaultMaxBodySize>>10, "Максимальный размер страницы в КБ, большие страницы пропускаются")
+	fs.StringVar(&o.userAgent, "user-agent", parser.DefaultUserAgent, "User-Agent HTTP запросов")
+	return o
+}
+
+// validate проверяет значения флагов HTTP клиента
+func (o *httpOptions) validate() error {
+	switch {
+	case o.timeout < 1:
+		return fmt.Errorf("-timeout должен быть больше 0")
+	case o.maxBodyKB < 1:
+		return fmt.Errorf("-max-body-kb должен быть больше 0")
+	case o.negativeTTL < 0:
+		return fmt.Errorf("-negative-ttl не может быть отрицательным")
+	}
+	return nil
+}
+
+// config создает конфигурацию парсера с настройками HTTP клиента из флагов
+func (o *httpOptions) config() (parser.ParserConfig, error) {
+	config := parser.DefaultConfig()
+	config.RequestTimeout = time.Duration(o.timeout) * time.Second
+	config.MaxBodySize = o.maxBodyKB << 10
+	config.UserAgent = o.userAgent
+	config.ResponseHeaderTimeout = config.RequestTimeout
+
+	// Открываем HTTP кеш
+	if o.cacheDir != "" {
+		cache, err := parser.NewHTTPCache(o.cacheDir, o.negativeTTL)
+		if err != nil {
+			return config, fmt.Errorf("ошибка при открытии кеша: %w", err)
+		}
+		cache.Seal(outputSealer)
+		config.Cache = cache
+	}
+
+	return config, nil
+}
+
+// scanOptions - параметры сканирования Telegraph
+type scanOptions struct {
+	http          *httpOptions
+	concurrent    int
+	retry         int
+	delay         int
+	months        string
+	noTranslit    bool
+	ignoreFile    string
+	maxErrorRatio float64
+}
+
+// addScanFlags регистрирует флаги многопоточности и конфигурации сканирования
+func addScanFlags(fs *flag.FlagSet) *scanOptions {
+	o := &scanOptions{http: addHTTPFlags(fs)}
+	fs.IntVar(&o.concurrent, "concurrent", 10, "Максимальное количество одновременных запросов (и соединений с telegra.ph)")
+	fs.IntVar(&o.retry, "retry", 3, "Количество повторных попыток при ошибке")
+	fs.IntVar(&o.delay, "delay", 100, "Задержка между запросами в миллисекундах")
+	fs.StringVar(&o.months, "months", "", "Месяцы для поиска (через запятую, например: 1,2,3)")
+	fs.BoolVar(&o.noTranslit, "no-translit", false, "Отключить транслитерацию запроса")
+	fs.StringVar(&o.ignoreFile, "ignore-file", "", "Файл с правилами игнорирования вместо встроенного списка, строка: <id> <literal|regex|domain> <области> <шаблон>.\n"+
+		"Области через запятую: title, text и links статьи, html - весь HTML страницы (как у встроенного списка) или all")
+	fs.Float64Var(&o.maxErrorRatio, "max-error-ratio", 0.2, "Допустимая доля ошибок проверки ссылок (0-1), при превышении код выхода 3")
+	return o
+}
+
+// validate проверяет значения флагов сканирования
+func (o *scanOptions) validate() error {
+	if err := o.http.validate(); err != nil {
+		return err
+	}
+
+	switch {
+	case o.concurrent < 1:
+		return fmt.Errorf("-concurrent должен быть больше 0")
+	case o.retry < 0:
+		return fmt.Errorf("-retry не может быть отрицательным")
+	case o.delay < 0:
+		return fmt.Errorf("-delay не может быть отрицательным")
+	case o.maxErrorRatio < 0 || o.maxErrorRatio > 1:
+		return fmt.Errorf("-max-error-ratio должен быть в диапазоне 0-1")
+	}
+
+	_, err := parseMonths(o.months)
+	return err
+}
+
+// config создает конфигурацию парсера из флагов
+func (o *scanOptions) config() (parser.ParserConfig, error) {
+	config, err := o.http.config()
+	if err != nil {
+		return config, err
+	}
+
+	// Применяем настройки из флагов командной строки
+	config.MaxConcurrentRequests = int64(o.concurrent)
+	config.RetryCount = o.retry
+	config.DelayBetweenRequests = time.Duration(o.delay) * time.Millisecond
+	config.IncludeTranslitVariants = !o.noTranslit
+
+	months, err := parseMonths(o.months)
+	if err != nil {
+		return config, err
+	}
+	config.MonthsToSearch = months
+
+	// Загружаем правила игнорирования из файла
+	if o.ignoreFile != "" {
+		rules, err := parser.LoadIgnoreRules(o.ignoreFile)
+		if err != nil {
+			return config, fmt.Errorf("ошибка при загрузке правил игнорирования: %w", err)
+		}
+		config.IgnoreRules = rules
+	}
+
+	return config, nil
+}
+
+// parseMonths разбирает флаг -months (пусто - все месяцы)
+func parseMonths(value string) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	months, err := parser.ParseMonths(value)
+	if err != nil {
+		return nil, fmt.Errorf("-months: %w", err)
+	}
+	return months, nil
+}
+
+// findingOptions - параметры поиска аккаунтов, вебхуков и секретов в статьях
+type findingOptions struct {
+	accounts           bool
+	webhooks           bool
+	secretRules        string
+	brand              bool
+	brandTerms         string
+	accountsType       string
+	webhookType        string
+	services           bool
+	fingerprintKeyFile string
+	output             string
+	workers            int
+	watchlist          *watchlistOptions
+	misp               *mispOptions   // Только для search и analyze
+	syslog             *syslogOptions // Только для search и analyze
+}
+
+// addFindingFlags регистрирует флаги поиска данных в статьях
+func addFindingFlags(fs *flag.FlagSet) *findingOptions {
+	o := &findingOptions{}
+	fs.BoolVar(&o.accounts, "accounts", false, "Искать аккаунты в статьях")
+	fs.BoolVar(&o.webhooks, "webhooks", false, "Искать вебхуки в статьях")
+	fs.BoolVar(&o.brand, "brand", false, "Искать в тексте статей упоминания наших доменов и терминов, включая написание кириллицей")
+	fs.StringVar(&o.brandTerms, "brand-terms", "", "Файл с дополнительными терминами для -brand: <домен> <термин> [термин...]")
+	fs.StringVar(&o.secretRules, "secret-rules", "", "Файл с правилами наших форматов секретов (регулярное выражение, контрольная сумма, энтропия, важность)")
+	fs.StringVar(&o.accountsType, "type", "all", "Тип аккаунтов ("+strings.Join(accountTypes, ", ")+")")
+	fs.StringVar(&o.webhookType, "webhook-type", "all", "Тип вебхуков ("+strings.Join(webhookTypes, ", ")+")")
+	fs.BoolVar(&o.services, "include-services", false,
+		"Сообщать об аккаунтах Minecraft и вебхуках Discord, GitHub и Slack, даже если домена сервиса нет в -watchlist\n"+
+			"(включается выбором такого типа в -type или -webhook-type)")
+	fs.StringVar(&o.fingerprintKeyFile, "fingerprint-key-file", "",
+		"Файл с ключом HMAC для отпечатков находок (по умолчанию $"+fingerprintKeyEnv+
+			" или ключ в каталоге настроек пользователя, созданный при первом запуске)")
+	fs.StringVar(&o.output, "o", "results.txt", "Файл для сохранения найденных данных")
+	o.watchlist = addWatchlistFlags(fs)
+	o.workers = 1
+	return o
+}
+
+// enabled сообщает, включен ли поиск аккаунтов, вебхуков, секретов или упоминаний
+func (o *findingOptions) enabled() bool {
+	return o.accounts || o.webhooks || o.secretRules != "" || o.brand
+}
+
+// detectorNames возвращает имена детекторов, включенных флагами -accounts и -webhooks
+func (o *findingOptions) detectorNames() []string {
+	var names []string
+	if o.accounts {
+		names = append(names, "accounts")
+	}
+	if o.webhooks {
+		names = append(names, "webhooks")
+	}
+	return names
+}
+
+// includeServices сообщает, нужны ли находки на доменах сервисов: по флагу или по выбранному типу
+func (o *findingOptions) includeServices() bool {
+	_, account := parser.ServiceOwner("accounts", o.accountsType)
+	_, webhook := parser.ServiceOwner("webhooks", o.webhookType)
+	return o.services || account || webhook
+}
+
+// addWorkersFlag регистрирует флаг параллельного анализа (для search и analyze)
+func (o *findingOptions) addWorkersFlag(fs *flag.FlagSet) {
+	fs.IntVar(&o.workers, "analyze-workers", 8, "Количество параллельных процессов для анализа результатов")
+}
+
+// mispOptions - параметры экспорта находок в событие MISP
+type mispOptions struct {
+	export bool
+	url    string
+	keyEnv string
+	tlp    string
+}
+
+// addMISPFlags регистрирует флаги экспорта в MISP (для search и analyze)
+func (o *findingOptions) addMISPFlags(fs *flag.FlagSet) {
+	o.misp = &mispOptions{}
+	fs.BoolVar(&o.misp.export, "misp", false, "Сохранить событие MISP со страницами, хешами и числом находок в <o>.misp.json")
+	fs.StringVar(&o.misp.url, "misp-url", "", "Адрес MISP для отправки события (включает -misp)")
+	fs.StringVar(&o.misp.keyEnv, "misp-key-env", "MISP_API_KEY", "Переменная окружения с ключом API MISP")
+	fs.StringVar(&o.misp.tlp, "misp-tlp", "amber", "Тег TLP события ("+strings.Join(tlpLevels(), ", ")+", none)")
+}
+
+// enabled сообщает, нужно ли строить событие MISP
+func (o *mispOptions) enabled() bool {
+	return o != nil && (o.export || o.url != "")
+}
+
+// validate проверяет флаги MISP
+func (o *mispOptions) validate() error {
+	if !o.enabled() {
+		return nil
+	}
+	if _, ok := parser.TLPMarkings[o.tlp]; !ok && o.tlp != "none" {
+		return fmt.Errorf("неизвестная отметка TLP %q", o.tlp)
+	}
+	if o.url != "" {
+		if u, err := url.Parse(o.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("некорректный адрес -misp-url %q", o.url)
+		}
+		if os.Getenv(o.keyEnv) == "" {
+			return fmt.Errorf("для -misp-url задайте ключ API в переменной окружения %s", o.keyEnv)
+		}
+	}
+	return nil
+}
+
+// syslogOptions - параметры отправки находок в SIEM по syslog
+type syslogOptions struct {
+	addr     string
+	network  string
+	baseline string
+}
+
+// addSyslogFlags регистрирует флаги отправки в syslog (для search и analyze)
+func (o *findingOptions) addSyslogFlags(fs *flag.FlagSet) {
+	o.syslog = &syslogOptions{}
+	fs.StringVar(&o.syslog.addr, "syslog", "", "Адрес приемника syslog (хост:порт) для отправки находок в формате RFC 5424 + CEF")
+	fs.StringVar(&o.syslog.network, "syslog-network", "udp", "Протокол syslog (udp, tcp)")
+	fs.StringVar(&o.syslog.baseline, "syslog-baseline", "", "JSON экспорты находок прошлого запуска через запятую: отправляются только новые находки")
+}
+
+// enabled сообщает, нужно ли отправлять находки в syslog
+func (o *syslogOptions) enabled() bool {
+	return o != nil && o.addr != ""
+}
+
+// validate проверяет флаги syslog
+func (o *syslogOptions) validate() error {
+	if !o.enabled() {
+		if o != nil && o.baseline != "" {
+			return fmt.Errorf("-syslog-baseline используется вместе с -syslog")
+		}
+		return nil
+	}
+	if o.network != "udp" && o.network != "tcp" {
+		return fmt.Errorf("неизвестный протокол -syslog-network %q, допустимо: udp, tcp", o.network)
+	}
+	if _, _, err := net.SplitHostPort(o.addr); err != nil {
+		return fmt.Errorf("некорректный адрес -syslog %q: %w", o.addr, err)
+	}
+	return nil
+}
+
+// validate проверяет значения флагов поиска данных
+func (o *findingOptions) validate() error {
+	if err := o.misp.validate(); err != nil {
+		return err
+	}
+	if err := o.syslog.validate(); err != nil {
+		return err
+	}
+	if (o.misp.enabled() || o.syslog.enabled()) && !o.enabled() {
+		return fmt.Errorf("-misp, -misp-url и -syslog используются вместе с -accounts, -webhooks, -secret-rules или -brand")
+	}
+	if !contains(accountTypes, o.accountsType) {
+		return fmt.Errorf("неизвестный тип аккаунтов %q, допустимо: %s", o.accountsType, strings.Join(accountTypes, ", "))
+	}
+	if !contains(webhookTypes, o.webhookType) {
+		return fmt.Errorf("неизвестный тип вебхуков %q, допустимо: %s", o.webhookType, strings.Join(webhookTypes, ", "))
+	}
+	if o.workers < 1 {
+		return fmt.Errorf("-analyze-workers должен быть больше 0")
+	}
+	if o.output == "" {
+		return fmt.Errorf("-o не может быть пустым")
+	}
+	if o.enabled() && !o.watchlist.set() {
+		return fmt.Errorf("для -accounts, -webhooks, -secret-rules и -brand нужен список наших доменов: -watchlist или -watchlist-file")
+	}
+	if o.brandTerms != "" && !o.brand {
+		return fmt.Errorf("-brand-terms используется вместе с -brand")
+	}
+	return nil
+}
+
+// watchlistOptions - домены, которым должны принадлежать находки
+type watchlistOptions struct {
+	domains string
+	file    string
+}
+
+// addWatchlistFlags регистрирует флаги списка доменов
+func addWatchlistFlags(fs *flag.FlagSet) *watchlistOptions {
+	o := &watchlistOptions{}
+	fs.StringVar(&o.domains, "watchlist", "", "Наши домены через запятую: о находках других доменов не сообщается")
+	fs.StringVar(&o.file, "watchlist-file", "", "Файл с нашими доменами, по одному на строку")
+	return o
+}
+
+// set сообщает, указан ли список доменов
+func (o *watchlistOptions) set() bool {
+	return strings.TrimSpace(o.domains) != "" || o.file != ""
+}
+
+// load собирает список доменов из флага и файла
+func (o *watchlistOptions) load() (*parser.Watchlist, error) {
+	watchlist := parser.NewWatchlist(strings.Split(o.domains, ","))
+	if o.file != "" {
+		loaded, err := parser.LoadWatchlist(o.file)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при загрузке списка доменов: %w", err)
+		}
+		watchlist = parser.NewWatchlist(append(watchlist.Domains(), loaded.Domains()...))
+	}
+	return watchlist, nil
+}
+
+// contains проверяет наличие значения в списке
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
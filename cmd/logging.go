@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"telegraph-finder-go/metrics"
+	"telegraph-finder-go/parser"
+)
+
+// addLogFlags регистрирует флаги уровня и формата журнала
+func addLogFlags(fs *flag.FlagSet) {
+	fs.String("log-level", "info", "Уровень журнала (debug, info, warn, error)")
+	fs.String("log-format", "text", "Формат журнала в stderr (text, json)")
+}
+
+// parseFlags разбирает флаги подкоманды, настраивает журнал, журнал аудита и ключ шифрования выходных файлов
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	fs.Parse(args)
+	setupAudit(fs)
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(fs.Lookup("log-level").Value.String())); err != nil {
+		return fmt.Errorf("некорректный -log-level: %w", err)
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format := strings.ToLower(fs.Lookup("log-format").Value.String()); format {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, options)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	default:
+		return fmt.Errorf("некорректный -log-format %q, допустимо: text, json", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return loadKey(fs)
+}
+
+// addMetricsFlag регистрирует флаг адреса для /metrics
+func addMetricsFlag(fs *flag.FlagSet) *string {
+	return fs.String("metrics-addr", "", "Адрес для HTTP эндпоинта /metrics в формате Prometheus (например, :9090)")
+}
+
+// startMetrics запускает HTTP сервер с /metrics. Если адрес пуст, метрики не собираются.
+func startMetrics(addr string) (parser.Metrics, error) {
+	if addr == "" {
+		return nil, nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть адрес метрик: %w", err)
+	}
+
+	collector := metrics.New()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector.Handler())
+
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			slog.Error("Сервер метрик остановлен", "error", err)
+		}
+	}()
+
+	slog.Info("Метрики доступны", "addr", listener.Addr().String(), "path", "/metrics")
+	return collector, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegraph-finder-go/parser"
+)
+
+// displayFindings отображает находки указанного типа
+func displayFindings(findings []parser.Finding, typeFilter string) {
+	for i, finding := range filterFindings(findings, typeFilter) {
+		fmt.Printf("%d. [%s] %s → %s (%s)\n", i+1, findingLabel(finding), finding.Value, finding.Owner, finding.Source)
+	}
+}
+
+// saveFindingsToFile сохраняет находки в файл. Секреты уже замаскированы детекторами.
+func saveFindingsToFile(findings []parser.Finding, filename string, typeFilter string) error {
+	var text bytes.Buffer
+
+	// Форматируем и фильтруем находки
+	filteredFindings := filterFindings(findings, typeFilter)
+
+	// Сохраняем в текстовом формате
+	for i, finding := range filteredFindings {
+		fmt.Fprintf(&text, "%d. [%s] %s → %s (%s)\n", i+1, findingLabel(finding), finding.Value, finding.Owner, finding.Source)
+	}
+
+	if err := writeOutput(filename, text.Bytes()); err != nil {
+		return err
+	}
+
+	// Дополнительно сохраняем в JSON
+	return writeJSONOutput(filename+".json", filteredFindings)
+}
+
+// findingLabel возвращает тип находки, важность, если она задана правилом,
+// и найденное написание термина
+func findingLabel(finding parser.Finding) string {
+	label := finding.Type
+	if finding.Severity != "" {
+		label += ", " + finding.Severity
+	}
+	if finding.Match != "" {
+		label += ": " + finding.Match
+	}
+	return label
+}
+
+// filterFindings оставляет находки указанного типа
+func filterFindings(findings []parser.Finding, typeFilter string) []parser.Finding {
+	var filtered []parser.Finding
+	for _, finding := range findings {
+		if typeFilter != "" && typeFilter != "all" && finding.Type != typeFilter {
+			continue
+		}
+		filtered = append(filtered, finding)
+	}
+	return filtered
+}
+
+// findingsByDetector оставляет находки указанного детектора
+func findingsByDetector(findings []parser.Finding, detector string) []parser.Finding {
+	var selected []parser.Finding
+	for _, finding := range findings {
+		if finding.Detector == detector {
+			selected = append(selected, finding)
+		}
+	}
+	return selected
+}
+
+// saveArticlesToFile сохраняет найденные статьи в текстовом формате и в JSON
+func saveArticlesToFile(articles []parser.Article, filename string) error {
+	var text bytes.Buffer
+
+	// Сохраняем в текстовом формате
+	for i, article := range articles {
+		tag := article.Query
+		if article.Keyword != "" && article.Keyword != article.Query {
+			tag = article.Keyword + " → " + article.Query
+		}
+		if article.Author != "" {
+			tag += ", автор: " + article.Author
+		}
+		if article.PublishedAt != nil {
+			tag += ", опубликовано: " + article.PublishedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(&text, "%d. %s [%s]\n", i+1, article, tag)
+	}
+
+	if err := writeOutput(filename, text.Bytes()); err != nil {
+		return err
+	}
+
+	// Дополнительно сохраняем в JSON
+	return writeJSONOutput(filename+".json", articles)
+}
+
+// displayCampaigns выводит кампании со всеми зеркалами
+func displayCampaigns(campaigns []parser.Campaign) {
+	for i, campaign := range campaigns {
+		fmt.Printf("\n%d. Кампания %.12s: %s, зеркал: %d\n", i+1, campaign.ID, campaign.Articles[0].Title, len(campaign.Articles))
+		for _, article := range campaign.Articles {
+			fmt.Printf("   %s (%02d.%02d)\n", article.URL, article.Day, article.Month)
+		}
+	}
+}
+
+// saveCampaignsToFile сохраняет кампании с зеркалами в текстовом формате и в JSON
+func saveCampaignsToFile(campaigns []parser.Campaign, filename string) error {
+	var text bytes.Buffer
+
+	// Сохраняем в текстовом формате: кампания и ссылки всех зеркал
+	for i, campaign := range campaigns {
+		fmt.Fprintf(&text, "%d. [%.12s] %s (зеркал: %d)\n", i+1, campaign.ID, campaign.Articles[0].Title, len(campaign.Articles))
+		for _, url := range campaign.URLs() {
+			fmt.Fprintf(&text, "   %s\n", url)
+		}
+	}
+
+	if err := writeOutput(filename, text.Bytes()); err != nil {
+		return err
+	}
+
+	// Дополнительно сохраняем в JSON
+	return writeJSONOutput(filename+".json", campaigns)
+}
+
+// saveSkipsToFile сохраняет журнал пропущенных страниц с идентификаторами правил и причинами пропуска
+func saveSkipsToFile(skips []parser.SkipRecord, filename string) error {
+	var text bytes.Buffer
+
+	// Сохраняем в текстовом формате
+	for i, skip := range skips {
+		detail := skip.Scope
+		if skip.Reason != parser.SkipIgnoreRule {
+			detail = skip.Detail
+		}
+		fmt.Fprintf(&text, "%d. [%s] %s (%s)\n", i+1, skip.Label(), skip.URL, detail)
+	}
+
+	if err := writeOutput(filename, text.Bytes()); err != nil {
+		return err
+	}
+
+	// Дополнительно сохраняем в JSON
+	return writeJSONOutput(filename+".json", skips)
+}
+
+// printPlan выводит план сканирования: каждый запрос пакета с выборкой из sample ссылок
+// (0 - все ссылки) и общую оценку нагрузки
+func printPlan(plan parser.BatchPlan, config parser.ParserConfig, sample int) {
+	fmt.Println("План сканирования (без сетевых запросов):")
+	for i, entry := range plan.Entries {
+		if len(plan.Entries) > 1 {
+			fmt.Printf("\nЗапрос %d из %d: %s\n", i+1, len(plan.Entries), entry.Queries[0])
+		}
+		printPlanEntry(entry, entry.URLs(sample))
+	}
+
+	fmt.Println()
+	if len(plan.Entries) > 1 {
+		fmt.Printf("Всего запросов: %d (до %d повторных при ошибках)\n", plan.Requests, plan.MaxRetries)
+	}
+	fmt.Printf("  Одновременных задач: %d, задержка: %v, таймаут: %v\n",
+		config.MaxConcurrentRequests, config.DelayBetweenRequests, config.RequestTimeout)
+	fmt.Printf("  Оценка скорости: %.1f запросов/сек, длительность: %v\n", plan.Rate, plan.ETA.Round(time.Second))
+}
+
+// printPlanEntry выводит план одного запроса и выборку его ссылок
+func printPlanEntry(plan parser.Plan, urls []string) {
+	months := make([]string, len(plan.Months))
+	for i, month := range plan.Months {
+		months[i] = strconv.Itoa(month)
+	}
+
+	fmt.Printf("  Варианты запроса: %s\n", strings.Join(plan.Queries, ", "))
+	fmt.Printf("  Месяцы: %s\n", strings.Join(months, ", "))
+	fmt.Printf("  Дни: 1-%d, индексы: %d-%d (индекс %d - ссылка без номера)\n",
+		plan.Days, plan.FirstIndex, plan.LastIndex, plan.FirstIndex)
+	fmt.Printf("  Запросов: %d (до %d повторных при ошибках)\n", plan.Requests, plan.MaxRetries)
+
+	if int64(len(urls)) < plan.Requests {
+		fmt.Printf("  Ссылки (выборка %d из %d):\n", len(urls), plan.Requests)
+	} else {
+		fmt.Println("  Ссылки:")
+	}
+	for _, url := range urls {
+		fmt.Printf("    %s\n", url)
+	}
+}
+
+// printProgress выводит строку прогресса со счетчиками, скоростью и оставшимся временем
+func printProgress(label string, p parser.Progress) {
+	percent := p.Percent()
+	progressBar := createProgressBar(percent, 20) // 20 символов в полоске прогресса
+	fmt.Printf("\r%s: [%s] %d/%d (%d%%) найдено: %d, ошибок: %d, повторов: %d, %.1f/сек, осталось: %s ",
+		label, progressBar, p.Probed, p.Total, percent, p.Hits, p.Errors, p.Retries,
+		p.Rate, p.ETA.Round(time.Second))
+}
+
+// saveErrorsToFile сохраняет ошибки проверки ссылок с классами
+func saveErrorsToFile(errs []parser.URLError, filename string) error {
+	var text bytes.Buffer
+
+	// Сохраняем в текстовом формате
+	for i, e := range errs {
+		fmt.Fprintf(&text, "%d. [%s] %s (%s)\n", i+1, e.Class, e.URL, e.Message)
+	}
+
+	if err := writeOutput(filename, text.Bytes()); err != nil {
+		return err
+	}
+
+	// Дополнительно сохраняем в JSON
+	return writeJSONOutput(filename+".json", errs)
+}
+
+// createProgressBar создает текстовую полоску прогресса определенной длины
+func createProgressBar(percent int, width int) string {
+	completed := width * percent / 100
+	if completed > width {
+		completed = width
+	}
+
+	bar := strings.Repeat("█", completed) + strings.Repeat("░", width-completed)
+	return bar
+}
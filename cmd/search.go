@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"telegraph-finder-go/parser"
+)
+
+// runSearch ищет статьи по запросу и при необходимости анализирует найденное
+func runSearch(args []string) int {
+	fs := newFlagSet("search", "search [флаги] <запрос> | search -batch <файл> [флаги]",
+		"Перебирает ссылки Telegraph вида <запрос>-ММ-ДД[-N] и сохраняет найденные статьи в <o> и <o>.json,\n"+
+			"перепубликации одного текста сводятся в кампании в <o>.campaigns.\n"+
+			"С -batch запросы читаются из файла, по одному в строке: <запрос> [months=1,2] [translit=on|off] [index=1-10];\n"+
+			"все запросы выполняются с общим ограничением параллельности, статьи помечаются исходным запросом.\n"+
+			"С -accounts, -webhooks, -secret-rules и/или -brand найденные статьи анализируются, результаты сохраняются\n"+
+			"в <o>.accounts, <o>.webhooks, <o>.secrets и <o>.brand.")
+	queryFlag := fs.String("q", "", "Поисковый запрос (можно передать аргументами)")
+	batchFlag := fs.String("batch", "", "Файл с запросами для пакетного поиска")
+	findOpts := addFindingFlags(fs)
+	findOpts.addWorkersFlag(fs)
+	findOpts.addMISPFlags(fs)
+	findOpts.addSyslogFlags(fs)
+	scanOpts := addScanFlags(fs)
+	summaryFlag := addSummaryFlag(fs)
+	metricsFlag := addMetricsFlag(fs)
+	dryRun := fs.Bool("dry-run", false, "Показать план сканирования и оценку нагрузки без сетевых запросов")
+	dryRunSample := fs.Int("dry-run-sample", 20, "Сколько ссылок каждого запроса показать с -dry-run (0 - все)")
+	dryRunLatency := fs.Duration("dry-run-latency", 300*time.Millisecond, "Ожидаемое время ответа для оценки длительности с -dry-run")
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	// Проверка наличия поискового запроса
+	query := *queryFlag
+	if query == "" {
+		query = strings.Join(fs.Args(), " ")
+	} else if fs.NArg() > 0 {
+		return usageError(fs, "запрос указан и в -q, и аргументами")
+	}
+	if *batchFlag != "" && strings.TrimSpace(query) != "" {
+		return usageError(fs, "запрос указан вместе с -batch")
+	}
+	if *batchFlag == "" && strings.TrimSpace(query) == "" {
+		return usageError(fs, "не указан поисковый запрос")
+	}
+
+	if err := findOpts.validate(); err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if err := scanOpts.validate(); err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if *dryRunSample < 0 {
+		return usageError(fs, "-dry-run-sample не может быть отрицательным")
+	}
+
+	summary := newSummary("search")
+	summary.Query = query
+
+	// Создаем конфигурацию парсера
+	config, err := scanOpts.config()
+	if err != nil {
+		slog.Error("Ошибка конфигурации", "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+
+	// Загружаем пакет запросов или ищем по одному запросу
+	batch := []parser.BatchQuery{{Query: query}}
+	if *batchFlag != "" {
+		batch, err = parser.LoadBatch(*batchFlag)
+		if err != nil {
+			slog.Error("Ошибка при чтении пакетного файла", "file", *batchFlag, "error", err)
+			summary.fail(err)
+			return summary.finish(exitError, *summaryFlag)
+		}
+
+		queries := make([]string, len(batch))
+		for i, entry := range batch {
+			queries[i] = entry.Query
+		}
+		summary.Query = strings.Join(queries, ", ")
+	}
+
+	// План сканирования без сетевых запросов
+	if *dryRun {
+		printPlan(parser.NewBatchPlan(batch, config, *dryRunLatency), config, *dryRunSample)
+		return summary.finish(exitOK, *summaryFlag)
+	}
+
+	// Запускаем эндпоинт /metrics, если указан адрес
+	config.Metrics, err = startMetrics(*metricsFlag)
+	if err != nil {
+		slog.Error("Ошибка запуска метрик", "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+
+	// Журнал пропущенных страниц: правила игнорирования и ограничения загрузки
+	var skips []parser.SkipRecord
+	var skipsMu sync.Mutex
+	config.OnSkip = func(skip parser.SkipRecord) {
+		skipsMu.Lock()
+		skips = append(skips, skip)
+		skipsMu.Unlock()
+	}
+
+	// Создаем контекст с возможностью отмены
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Вывод информации о запросе и конфигурации
+	if *batchFlag != "" {
+		slog.Info("Начинаю пакетный поиск статей", "file", *batchFlag, "queries", len(batch),
+			"concurrent", config.MaxConcurrentRequests, "timeout", config.RequestTimeout,
+			"delay", config.DelayBetweenRequests)
+	} else {
+		translit := ""
+		if config.IncludeTranslitVariants {
+			translit = parser.Translit(query)
+		}
+		slog.Info("Начинаю поиск статей", "query", query, "translit", translit,
+			"concurrent", config.MaxConcurrentRequests, "timeout", config.RequestTimeout,
+			"delay", config.DelayBetweenRequests, "months", config.MonthsToSearch)
+	}
+
+	// Запускаем потоковый поиск и выводим найденные статьи по мере появления
+	startTime := time.Now()
+	var results []parser.Article
+	var lastProgress parser.Progress
+	seen := make(map[string]bool)
+	errorReport := parser.NewErrorReport()
+
+	// Один клиент и транспорт для поиска и анализа найденных статей
+	client := parser.NewClient(config)
+	for event := range parser.New(config, parser.WithClient(client)).SearchBatch(ctx, batch) {
+		switch event.Kind {
+		case parser.EventArticle:
+			results = append(results, event.Article)
+			// Одна и та же страница может найтись по обоим вариантам запроса
+			if !seen[event.Article.CanonicalURL] {
+				seen[event.Article.CanonicalURL] = true
+				fmt.Printf("\rНайдена статья: %s\n", event.Article)
+			}
+		case parser.EventError:
+			errorReport.Add(event.URL, event.Err)
+		case parser.EventProgress:
+			lastProgress = event.Progress
+			printProgress("Прогресс", event.Progress)
+		}
+	}
+
+	// Печатаем новую строку после завершения прогресса
+	fmt.Println()
+
+	if err := ctx.Err(); err != nil {
+		slog.Error("Ошибка при поиске", "error", err)
+		summary.fail(err)
+		return summary.finish(exitError, *summaryFlag)
+	}
+
+	// Удаляем дубликаты и сортируем по месяцу, дню и индексу
+	results = parser.SortArticles(results)
+
+	// Выводим статистику
+	duration := time.Since(startTime).Round(time.Second)
+	slog.Info("Поиск завершен", "articles", len(results), "urls", lastProgress.Probed,
+		"duration", duration, "rate", fmt.Sprintf("%.1f/сек", lastProgress.Rate))
+
+	// Сводка ошибок по классам
+	errorRatio := 0.0
+	if lastProgress.Probed > 0 {
+		errorRatio = float64(errorReport.Total()) / float64(lastProgress.Probed)
+	}
+	summary.Counts.URLsProbed = lastProgress.Probed
+	summary.Counts.Articles = len(results)
+	summary.Counts.Skipped = len(skips)
+	summary.Counts.Errors = errorReport.Total()
+	summary.ErrorsByClass = errorReport.Counts()
+	summary.ErrorRatio = errorRatio
+	if errorReport.Total() > 0 {
+		counts := errorReport.Counts()
+		var classes []any
+		for _, class := range errorReport.Classes() {
+			classes = append(classes, slog.Int(string(class), counts[class]))
+		}
+		filename := findOpts.output + ".errors"
+		slog.Warn("Ошибки при проверке ссылок", "errors", errorReport.Total(), "urls", lastProgress.Probed,
+			"ratio", fmt.Sprintf("%.1f%%", errorRatio*100), slog.Group("classes", classes...), "file", filename)
+		if err := saveErrorsToFile(errorReport.Errors(), filename); err != nil {
+			slog.Error("Ошибка при сохранении журнала ошибок", "file", filename, "error", err)
+		} else {
+			summary.addOutput(filename, filename+".json")
+		}
+	}
+
+	if len(skips) > 0 {
+		filename := findOpts.output + ".skipped"
+		slog.Info("Пропущено страниц", "pages", len(skips), "file", filename)
+		if err := saveSkipsToFile(skips, filename); err != nil {
+			slog.Error("Ошибка при сохранении журнала пропусков", "file", filename, "error", err)
+		} else {
+			summary.addOutput(filename, filename+".json")
+		}
+	}
+
+	partial := errorRatio > scanOpts.maxErrorRatio
+	analyzed := findOpts.enabled()
+
+	if len(results) > 0 {
+		fmt.Println("\nНайденные статьи:")
+		for i, article := range results {
+			fmt.Printf("%d. %s\n", i+1, article)
+		}
+
+		if err := saveArticlesToFile(results, findOpts.output); err != nil {
+			slog.Error("Ошибка при сохранении статей", "file", findOpts.output, "error", err)
+			summary.fail(err)
+			return summary.finish(exitError, *summaryFlag)
+		}
+		summary.addOutput(findOpts.output, findOpts.output+".json")
+
+		// Перепубликации одного текста под разными ссылками сводятся в кампании
+		filename := findOpts.output + ".campaigns"
+		if err := saveCampaignsToFile(parser.ClusterCampaigns(results, parser.DefaultCampaignDistance), filename); err != nil {
+			slog.Error("Ошибка при сохранении кампаний", "file", filename, "error", err)
+		} else {
+			summary.addOutput(filename, filename+".json")
+		}
+
+		// Если включен флаг поиска вебхуков или аккаунтов, запускаем параллельный анализ
+		if analyzed {
+			failed, err := analyzeAndSave(ctx, config, client, results, findOpts, summary)
+			if err != nil {
+				slog.Error("Ошибка при анализе", "error", err)
+				summary.fail(err)
+			}
+			// Статьи, которые не удалось проанализировать, могли содержать находки
+			partial = partial || err != nil || failed > 0
+		}
+	} else if partial {
+		slog.Warn("Статьи не найдены, но сайт был недоступен для значительной части ссылок")
+	} else {
+		fmt.Println("Статьи не найдены. Попробуйте другой запрос.")
+	}
+
+	// Проверка порога ошибок выполняется после вывода и сохранения результатов
+	if partial {
+		if errorRatio > scanOpts.maxErrorRatio {
+			slog.Warn("Доля ошибок превышает порог, результаты неполные",
+				"ratio", fmt.Sprintf("%.1f%%", errorRatio*100),
+				"threshold", fmt.Sprintf("%.1f%%", scanOpts.maxErrorRatio*100))
+		}
+		return summary.finish(exitPartial, *summaryFlag)
+	}
+	if summary.findings(analyzed) {
+		return summary.finish(exitFindings, *summaryFlag)
+	}
+	return summary.finish(exitOK, *summaryFlag)
+}
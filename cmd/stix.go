@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"telegraph-finder-go/parser"
+)
+
+// runSTIX экспортирует результаты сканирования и находки в набор STIX 2.1
+func runSTIX(args []string) int {
+	fs := newFlagSet("stix", "stix [флаги] <results.json> [находки.json...]",
+		"Экспортирует статьи из JSON экспорта команды search и находки (<o>.accounts.json, <o>.webhooks.json,\n"+
+			"<o>.secrets.json, <o>.brand.json) в набор STIX 2.1 для обмена с CERT: страницы - объекты url,\n"+
+			"кампании перепубликаций - grouping, находки - indicator и observed-data. Секреты остаются замаскированными.")
+	outputFlag := fs.String("o", "", "Файл для сохранения набора (по умолчанию - стандартный вывод)")
+	tlpFlag := fs.String("tlp", "amber", "Отметка TLP объектов ("+strings.Join(tlpLevels(), ", ")+", none)")
+	producerFlag := fs.String("producer", "telegraph-finder-go", "Имя источника данных в наборе")
+	distanceFlag := fs.Int("distance", parser.DefaultCampaignDistance, "Наибольшее расстояние Хэмминга между SimHash зеркал кампании")
+	if err := parseFlags(fs, args); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if fs.NArg() < 1 {
+		return usageError(fs, "ожидается файл с результатами поиска")
+	}
+	tlp := *tlpFlag
+	if tlp == "none" {
+		tlp = ""
+	} else if _, ok := parser.TLPMarkings[tlp]; !ok {
+		return usageError(fs, "неизвестная отметка TLP %q", tlp)
+	}
+
+	var articles []parser.Article
+	if err := loadJSON(fs.Arg(0), &articles); err != nil {
+		slog.Error("Ошибка при чтении результатов", "file", fs.Arg(0), "error", err)
+		return exitError
+	}
+
+	var findings []parser.Finding
+	for _, filename := range fs.Args()[1:] {
+		var loaded []parser.Finding
+		if err := loadJSON(filename, &loaded); err != nil {
+			slog.Error("Ошибка при чтении находок", "file", filename, "error", err)
+			return exitError
+		}
+		findings = append(findings, loaded...)
+	}
+
+	campaigns := parser.ClusterCampaigns(articles, *distanceFlag)
+	bundle, err := parser.NewSTIXBundle(articles, campaigns, findings, parser.STIXOptions{
+		Producer: *producerFlag,
+		TLP:      tlp,
+		Now:      time.Now(),
+	})
+	if err != nil {
+		slog.Error("Ошибка при построении набора STIX", "error", err)
+		return exitError
+	}
+
+	if *outputFlag == "" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(bundle); err != nil {
+			slog.Error("Ошибка при выводе набора STIX", "error", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	if err := writeJSONOutput(*outputFlag, bundle); err != nil {
+		slog.Error("Ошибка при сохранении набора STIX", "file", *outputFlag, "error", err)
+		return exitError
+	}
+	auditOutput(*outputFlag)
+	slog.Info("Набор STIX сохранен", "file", *outputFlag, "objects", len(bundle.Objects))
+	return exitOK
+}
+
+// tlpLevels возвращает уровни TLP в алфавитном порядке
+func tlpLevels() []string {
+	levels := make([]string, 0, len(parser.TLPMarkings))
+	for level := range parser.TLPMarkings {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	return levels
+}
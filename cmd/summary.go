@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"time"
+
+	"telegraph-finder-go/parser"
+)
+
+// runCounts - счетчики запуска для итоговой сводки
+type runCounts struct {
+	URLsProbed int64 `json:"urls_probed"`
+	Articles   int   `json:"articles"`
+	Skipped    int   `json:"skipped"`
+	Errors     int   `json:"errors"`
+	Accounts   int   `json:"accounts"`
+	Webhooks   int   `json:"webhooks"`
+	Secrets    int   `json:"secrets"`
+	Mentions   int   `json:"mentions"`
+}
+
+// runSummary - машиночитаемая сводка запуска для автоматизации
+type runSummary struct {
+	Command       string                    `json:"command"`
+	Query         string                    `json:"query,omitempty"`
+	URL           string                    `json:"url,omitempty"`
+	Status        string                    `json:"status"` // clean, findings, partial, failure
+	ExitCode      int                       `json:"exit_code"`
+	StartedAt     time.Time                 `json:"started_at"`
+	DurationSec   float64                   `json:"duration_seconds"`
+	Counts        runCounts                 `json:"counts"`
+	ErrorsByClass map[parser.ErrorClass]int `json:"errors_by_class,omitempty"`
+	ErrorRatio    float64                   `json:"error_ratio"`
+	OutputFiles   []string                  `json:"output_files"`
+	Failure       string                    `json:"failure,omitempty"`
+}
+
+// addSummaryFlag регистрирует флаг пути для JSON сводки
+func addSummaryFlag(fs *flag.FlagSet) *string {
+	return fs.String("summary-json", "", "Файл для машиночитаемой сводки запуска (JSON)")
+}
+
+// newSummary создает сводку запуска команды
+func newSummary(command string) *runSummary {
+	return &runSummary{
+		Command:     command,
+		StartedAt:   time.Now(),
+		OutputFiles: []string{},
+	}
+}
+
+// addOutput добавляет созданные файлы в сводку
+func (s *runSummary) addOutput(files ...string) {
+	s.OutputFiles = append(s.OutputFiles, files...)
+}
+
+// addErrors добавляет ошибки проверки ссылок в счетчики сводки
+func (s *runSummary) addErrors(errorReport *parser.ErrorReport) {
+	s.Counts.Errors += errorReport.Total()
+	if s.ErrorsByClass == nil {
+		s.ErrorsByClass = make(map[parser.ErrorClass]int)
+	}
+	for class, n := range errorReport.Counts() {
+		s.ErrorsByClass[class] += n
+	}
+}
+
+// fail запоминает причину неуспешного завершения
+func (s *runSummary) fail(err error) {
+	if s.Failure == "" {
+		s.Failure = err.Error()
+	}
+}
+
+// findings сообщает, найдены ли данные: аккаунты, вебхуки, секреты и упоминания, а без анализа - статьи
+func (s *runSummary) findings(analyzed bool) bool {
+	if analyzed {
+		return s.Counts.Accounts > 0 || s.Counts.Webhooks > 0 || s.Counts.Secrets > 0 || s.Counts.Mentions > 0
+	}
+	return s.Counts.Articles > 0
+}
+
+// finishError завершает запуск с ошибкой выполнения
+func (s *runSummary) finishError(err error, path string) int {
+	s.fail(err)
+	if class := parser.ClassifyError(err); class != "" {
+		s.Counts.Errors++
+		s.ErrorsByClass = map[parser.ErrorClass]int{class: 1}
+		if s.Counts.URLsProbed > 0 {
+			s.ErrorRatio = float64(s.Counts.Errors) / float64(s.Counts.URLsProbed)
+		}
+	}
+	return s.finish(exitError, path)
+}
+
+// finish определяет статус, записывает сводку в path (если задан) и возвращает код выхода
+func (s *runSummary) finish(exitCode int, path string) int {
+	s.ExitCode = exitCode
+	s.Status = exitStatus(exitCode)
+	s.DurationSec = time.Since(s.StartedAt).Seconds()
+
+	if path != "" {
+		if err := s.write(path); err != nil {
+			slog.Error("Ошибка при сохранении сводки", "file", path, "error", err)
+			exitCode = exitError
+		} else {
+			s.addOutput(path)
+		}
+	}
+
+	if err := s.audit(exitCode); err != nil {
+		slog.Error("Ошибка журнала аудита", "error", err)
+		return exitError
+	}
+	return exitCode
+}
+
+// audit записывает запуск в журнал аудита: запрос или ссылку, число находок и созданные файлы
+func (s *runSummary) audit(exitCode int) error {
+	if s.Query != "" {
+		auditTrail.record.Target = s.Query
+	} else if s.URL != "" {
+		auditTrail.record.Target = s.URL
+	}
+
+	counts := map[string]int{
+		"urls_probed": int(s.Counts.URLsProbed),
+		"articles":    s.Counts.Articles,
+		"skipped":     s.Counts.Skipped,
+		"errors":      s.Counts.Errors,
+		"accounts":    s.Counts.Accounts,
+		"webhooks":    s.Counts.Webhooks,
+		"secrets":     s.Counts.Secrets,
+		"mentions":    s.Counts.Mentions,
+	}
+	auditTrail.record.Findings = make(map[string]int)
+	for name, count := range counts {
+		if count > 0 {
+			auditTrail.record.Findings[name] = count
+		}
+	}
+
+	auditOutput(s.OutputFiles...)
+	return recordAudit(exitCode, s.Failure)
+}
+
+// write сохраняет сводку в файл
+func (s *runSummary) write(path string) error {
+	return writeJSONOutput(path, s)
+}
+
+// exitStatus возвращает название статуса для кода выхода
+func exitStatus(exitCode int) string {
+	switch exitCode {
+	case exitOK:
+		return "clean"
+	case exitFindings:
+		return "findings"
+	case exitPartial:
+		return "partial"
+	default:
+		return "failure"
+	}
+}
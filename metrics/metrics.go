@@ -0,0 +1,87 @@
+// Package metrics собирает метрики сканирования в формате Prometheus
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Collector реализует parser.Metrics поверх реестра Prometheus
+type Collector struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	latency      prometheus.Histogram
+	retries      prometheus.Counter
+	pagesFound   prometheus.Counter
+	pagesIgnored *prometheus.CounterVec
+	findings     *prometheus.CounterVec
+}
+
+// New создает коллектор с собственным реестром
+func New() *Collector {
+	c := &Collector{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "telegraph_finder_requests_total",
+			Help: "HTTP запросы к Telegraph по статусу ответа (error - ошибка соединения)",
+		}, []string{"status"}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "telegraph_finder_fetch_duration_seconds",
+			Help:    "Длительность HTTP запросов к Telegraph",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "telegraph_finder_retries_total",
+			Help: "Повторные попытки после ошибки запроса",
+		}),
+		pagesFound: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "telegraph_finder_pages_found_total",
+			Help: "Найденные статьи",
+		}),
+		pagesIgnored: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "telegraph_finder_pages_ignored_total",
+			Help: "Страницы, отброшенные правилами игнорирования",
+		}, []string{"rule"}),
+		findings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "telegraph_finder_findings_total",
+			Help: "Найденные данные по типу",
+		}, []string{"type"}),
+	}
+
+	c.registry.MustRegister(c.requests, c.latency, c.retries, c.pagesFound, c.pagesIgnored, c.findings)
+	return c
+}
+
+// ObserveRequest учитывает HTTP запрос
+func (c *Collector) ObserveRequest(status string, duration time.Duration) {
+	c.requests.WithLabelValues(status).Inc()
+	c.latency.Observe(duration.Seconds())
+}
+
+// IncRetry учитывает повторную попытку
+func (c *Collector) IncRetry() {
+	c.retries.Inc()
+}
+
+// IncPageFound учитывает найденную статью
+func (c *Collector) IncPageFound() {
+	c.pagesFound.Inc()
+}
+
+// IncPageIgnored учитывает страницу, отброшенную правилом
+func (c *Collector) IncPageIgnored(ruleID string) {
+	c.pagesIgnored.WithLabelValues(ruleID).Inc()
+}
+
+// IncFinding учитывает найденные данные указанного типа
+func (c *Collector) IncFinding(kind string) {
+	c.findings.WithLabelValues(kind).Inc()
+}
+
+// Handler возвращает HTTP обработчик для /metrics
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{})
+}
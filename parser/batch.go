@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxBatchIndex - верхняя граница индекса в пакетном файле
+const maxBatchIndex = 999
+
+// BatchQuery - запрос пакетного поиска с собственными настройками.
+// Незаданные настройки берутся из общей конфигурации.
+type BatchQuery struct {
+	Query      string
+	Months     []int // Месяцы для поиска (nil - из конфигурации)
+	Translit   *bool // Транслитерация запроса (nil - из конфигурации)
+	FirstIndex int   // Первый индекс (0 - из конфигурации)
+	LastIndex  int   // Последний индекс (0 - из конфигурации)
+}
+
+// apply возвращает конфигурацию с настройками запроса
+func (q BatchQuery) apply(config ParserConfig) ParserConfig {
+	if q.Months != nil {
+		config.MonthsToSearch = q.Months
+	}
+	if q.Translit != nil {
+		config.IncludeTranslitVariants = *q.Translit
+	}
+	if q.FirstIndex > 0 {
+		config.FirstIndex = q.FirstIndex
+	}
+	if q.LastIndex > 0 {
+		config.LastIndex = q.LastIndex
+	}
+	return config
+}
+
+// LoadBatch читает пакетный файл запросов.
+// Формат строки: <запрос> [months=1,2,3] [translit=on|off] [index=1-10]
+// Пустые строки и строки, начинающиеся с #, пропускаются.
+func LoadBatch(path string) ([]BatchQuery, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var batch []BatchQuery
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		query, err := parseBatchLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		batch = append(batch, query)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("%s: нет запросов", path)
+	}
+	return batch, nil
+}
+
+// parseBatchLine разбирает строку пакетного файла
+func parseBatchLine(line string) (BatchQuery, error) {
+	var query BatchQuery
+	var words []string
+
+	for _, field := range strings.Fields(line) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			words = append(words, field)
+			continue
+		}
+
+		switch key {
+		case "months":
+			months, err := ParseMonths(value)
+			if err != nil {
+				return query, err
+			}
+			query.Months = months
+		case "translit":
+			switch value {
+			case "on", "true", "yes":
+				enabled := true
+				query.Translit = &enabled
+			case "off", "false", "no":
+				enabled := false
+				query.Translit = &enabled
+			default:
+				return query, fmt.Errorf("некорректное значение translit %q, ожидается on или off", value)
+			}
+		case "index":
+			first, last, err := parseIndexRange(value)
+			if err != nil {
+				return query, err
+			}
+			query.FirstIndex, query.LastIndex = first, last
+		default:
+			return query, fmt.Errorf("неизвестный параметр %q", key)
+		}
+	}
+
+	query.Query = strings.Join(words, " ")
+	if query.Query == "" {
+		return query, fmt.Errorf("не указан запрос")
+	}
+	return query, nil
+}
+
+// ParseMonths разбирает список месяцев через запятую, например 1,2,3
+func ParseMonths(value string) ([]int, error) {
+	var months []int
+	for _, monthStr := range strings.Split(value, ",") {
+		month, err := strconv.Atoi(strings.TrimSpace(monthStr))
+		if err != nil || month < 1 || month > 12 {
+			return nil, fmt.Errorf("некорректный месяц %q, ожидается число 1-12", monthStr)
+		}
+		months = append(months, month)
+	}
+	return months, nil
+}
+
+// parseIndexRange разбирает диапазон индексов вида 1-10 или один индекс
+func parseIndexRange(value string) (int, int, error) {
+	firstStr, lastStr, isRange := strings.Cut(value, "-")
+	if !isRange {
+		lastStr = firstStr
+	}
+
+	first, err1 := strconv.Atoi(firstStr)
+	last, err2 := strconv.Atoi(lastStr)
+	if err1 != nil || err2 != nil || first < 1 || last < first || last > maxBatchIndex {
+		return 0, 0, fmt.Errorf("некорректный диапазон индексов %q, ожидается например 1-10 (не больше %d)", value, maxBatchIndex)
+	}
+	return first, last, nil
+}
+
+// batchTask - задача сканирования одного варианта запроса за месяц
+type batchTask struct {
+	keyword string
+	query   string
+	month   int
+	config  ParserConfig
+}
+
+// SearchBatch запускает пакетный поиск и возвращает канал событий.
+// Все запросы используют HTTP клиент и ограничитель парсера и общий счетчик прогресса;
+// найденные статьи помечаются исходным запросом в Article.Keyword.
+// Канал закрывается после завершения поиска или отмены ctx.
+func (p *Parser) SearchBatch(ctx context.Context, batch []BatchQuery) <-chan Event {
+	events := make(chan Event, streamBufferSize)
+
+	// emit отправляет событие, не блокируясь после отмены контекста
+	emit := func(event Event) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	go func() {
+		defer close(events)
+
+		// Раскладываем запросы на задачи запрос×месяц с учетом их настроек
+		var tasks []batchTask
+		for _, entry := range batch {
+			entryConfig := entry.apply(p.config)
+			for _, query := range searchQueries(entry.Query, entryConfig) {
+				for _, month := range searchMonths(entryConfig) {
+					tasks = append(tasks, batchTask{keyword: entry.Query, query: query, month: month, config: entryConfig})
+				}
+			}
+		}
+
+		// Число ссылок берется из плана, по которому -dry-run показывает оценку нагрузки
+		plan := NewBatchPlan(batch, p.config, 0)
+		tracker := NewProgressTracker(plan.Requests, p.config.ProgressInterval, func(progress Progress) {
+			emit(Event{Kind: EventProgress, Progress: progress})
+		})
+
+		// Создаем группу ошибок для синхронизации горутин. Проверки ссылок ограничены
+		// ограничителем парсера; задач одновременно не больше, чем проверок, остальные только ждали бы его.
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(int(max(p.config.MaxConcurrentRequests, 1)))
+
+		for _, task := range tasks {
+			task := task // Создаем локальную копию для горутины
+
+			// Помечаем найденные статьи исходным запросом
+			taskEmit := func(event Event) {
+				if event.Kind == EventArticle {
+					event.Article.Keyword = task.keyword
+				}
+				emit(event)
+			}
+
+			g.Go(func() error {
+				// Вносим задержку для предотвращения блокировки сервера
+				time.Sleep(task.config.DelayBetweenRequests)
+
+				return p.scanMonth(gctx, task.config, task.query, task.month, tracker, taskEmit)
+			})
+		}
+
+		g.Wait()
+
+		// Останавливаем отправку прогресса и передаем финальный снимок
+		tracker.Stop()
+	}()
+
+	return events
+}
@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// excerptRadius - сколько символов текста показывать с каждой стороны от упоминания
+const excerptRadius = 40
+
+// homoglyphs сопоставляет кириллические буквы латинским двойникам (после приведения к нижнему регистру)
+var homoglyphs = map[rune]rune{
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ь': 'b', 'і': 'i', 'ї': 'i',
+	'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'һ': 'h', 'ӏ': 'l', 'ԛ': 'q', 'ԝ': 'w',
+}
+
+// BrandTerm - наш термин (домен, продукт, бренд) и домен, которому он принадлежит
+type BrandTerm struct {
+	Term  string
+	Owner string
+}
+
+// LoadBrandTerms загружает термины из файла.
+// Формат строки: <наш домен> <термин> [термин...]
+// Пустые строки и строки, начинающиеся с #, пропускаются.
+func LoadBrandTerms(path string) ([]BrandTerm, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var terms []BrandTerm
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: ожидается <домен> <термин> [термин...]", path, lineNum)
+		}
+		for _, term := range fields[1:] {
+			terms = append(terms, BrandTerm{Term: term, Owner: fields[0]})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return terms, nil
+}
+
+// brandDetector ищет упоминания наших терминов в тексте и ссылках статьи
+type brandDetector struct {
+	terms []brandPattern
+}
+
+// brandPattern - термин, нормализованный для поиска
+type brandPattern struct {
+	term       string
+	normalized []rune
+	owner      string
+}
+
+// NewBrandDetector создает детектор упоминаний доменов из списка наблюдения и дополнительных терминов.
+// Поиск не учитывает регистр и кириллические буквы, похожие на латинские.
+func NewBrandDetector(watchlist *Watchlist, terms []BrandTerm) Detector {
+	for _, domain := range watchlist.Domains() {
+		terms = append(terms, BrandTerm{Term: domain, Owner: domain})
+	}
+
+	d := brandDetector{}
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		normalized := NormalizeHomoglyphs(strings.TrimSpace(term.Term))
+		if len(normalized) == 0 || seen[string(normalized)] {
+			continue
+		}
+		seen[string(normalized)] = true
+		d.terms = append(d.terms, brandPattern{
+			term:       term.Term,
+			normalized: normalized,
+			owner:      strings.TrimPrefix(strings.ToLower(strings.TrimSpace(term.Owner)), "."),
+		})
+	}
+	return d
+}
+
+// NormalizeHomoglyphs приводит текст к нижнему регистру и заменяет кириллические
+// двойники латинских букв. Каждому символу исходного текста соответствует один символ результата.
+func NormalizeHomoglyphs(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		r = unicode.ToLower(r)
+		if latin, ok := homoglyphs[r]; ok {
+			r = latin
+		}
+		runes[i] = r
+	}
+	return runes
+}
+
+// Name возвращает имя детектора
+func (brandDetector) Name() string {
+	return "brand"
+}
+
+// Detect ищет первое упоминание каждого термина в тексте и ссылках статьи.
+// Находка содержит отрывок текста вокруг упоминания с замаскированными секретами.
+func (d brandDetector) Detect(page Page) []Finding {
+	var findings []Finding
+	text := []rune(page.Text + "\n" + strings.Join(page.Links, "\n"))
+	normalized := NormalizeHomoglyphs(string(text))
+
+	for _, term := range d.terms {
+		start := indexRunes(normalized, term.normalized)
+		if start < 0 {
+			continue
+		}
+		end := start + len(term.normalized)
+		match := string(text[start:end])
+
+		// Написание с кириллическими двойниками - признак подделки
+		findingType := "mention"
+		if !strings.EqualFold(match, term.term) {
+			findingType = "homoglyph"
+		}
+
+		findings = append(findings, Finding{
+			Detector:    d.Name(),
+			Type:        findingType,
+			Domain:      term.owner,
+			Match:       match,
+			Value:       excerpt(text, start, end),
+			Fingerprint: fingerprint(d.Name(), term.term, page.URL),
+			Source:      page.URL,
+		})
+	}
+
+	return findings
+}
+
+// indexRunes возвращает позицию первого вхождения needle в text или -1
+func indexRunes(text, needle []rune) int {
+	for i := 0; i+len(needle) <= len(text); i++ {
+		found := true
+		for j, r := range needle {
+			if text[i+j] != r {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+// excerpt вырезает отрывок вокруг [start, end) по границам слов и маскирует в нем секреты
+func excerpt(text []rune, start, end int) string {
+	from := start - excerptRadius
+	if from < 0 {
+		from = 0
+	}
+	to := end + excerptRadius
+	if to > len(text) {
+		to = len(text)
+	}
+
+	// Расширяем до границ слов, чтобы секрет не попал в отрывок обрезанным и немаскированным
+	for limit := from - excerptRadius; from > 0 && from > limit && !unicode.IsSpace(text[from-1]); {
+		from--
+	}
+	for limit := to + excerptRadius; to < len(text) && to < limit && !unicode.IsSpace(text[to]); {
+		to++
+	}
+
+	result := strings.Join(strings.Fields(string(text[from:to])), " ")
+	if from > 0 {
+		result = "…" + result
+	}
+	if to < len(text) {
+		result += "…"
+	}
+	return RedactText(result)
+}
@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// cacheEntry - сохраненный на диске ответ
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	StatusCode   int       `json:"status_code"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Body         []byte    `json:"body,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+// HTTPCache - HTTP кеш на диске для повторных запусков.
+// Ответы 200 с ETag или Last-Modified перепроверяются условными запросами,
+// ответы 404 считаются отсутствующими статьями в течение negativeTTL без обращения к сети.
+type HTTPCache struct {
+	dir         string
+	negativeTTL time.Duration
+	sealer      *Sealer
+}
+
+// NewHTTPCache создает кеш в каталоге dir
+func NewHTTPCache(dir string, negativeTTL time.Duration) (*HTTPCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &HTTPCache{dir: dir, negativeTTL: negativeTTL}, nil
+}
+
+// Seal включает шифрование записей кеша: в них хранятся страницы с исходными, немаскированными данными
+func (c *HTTPCache) Seal(sealer *Sealer) {
+	c.sealer = sealer
+}
+
+// cacheTransport отвечает из кеша или выполняет условные запросы
+type cacheTransport struct {
+	base        http.RoundTripper
+	cache       *HTTPCache
+	maxBodySize int64 // Наибольший сохраняемый ответ
+}
+
+// CacheTransport оборачивает транспорт кешем. Сохраняются ответы не больше maxBodySize
+// (0 - DefaultMaxBodySize), как и у страниц, которые загружает парсер.
+// Если cache равен nil, транспорт возвращается без изменений.
+func CacheTransport(base http.RoundTripper, cache *HTTPCache, maxBodySize int64) http.RoundTripper {
+	if cache == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	return &cacheTransport{base: base, cache: cache, maxBodySize: maxBodySize}
+}
+
+// RoundTrip отвечает из кеша или выполняет условный запрос
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	entry := t.cache.load(key)
+
+	// Известный пустой слаг: не обращаемся к сети до истечения TTL
+	if entry != nil && entry.StatusCode == http.StatusNotFound && time.Since(entry.StoredAt) < t.cache.negativeTTL {
+		return entry.response(req), nil
+	}
+
+	// Условный запрос для сохраненной статьи
+	if entry != nil && entry.StatusCode == http.StatusOK {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if entry == nil || entry.StatusCode != http.StatusOK {
+			return resp, nil
+		}
+		resp.Body.Close()
+		entry.StoredAt = time.Now()
+		t.cache.store(key, entry)
+		return entry.response(req), nil
+
+	case http.StatusNotFound:
+		resp.Body.Close()
+		entry = &cacheEntry{URL: key, StatusCode: http.StatusNotFound, StoredAt: time.Now()}
+		t.cache.store(key, entry)
+		return entry.response(req), nil
+
+	case http.StatusOK:
+		etag := resp.Header.Get("ETag")
+		lastModified := resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			// Без валидаторов условный запрос невозможен, ответ не кешируем
+			return resp, nil
+		}
+
+		// Слишком большие ответы не кешируем, ограничение размера проверяет вызывающий код
+		body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBodySize+1))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if int64(len(body)) > t.maxBodySize {
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		entry = &cacheEntry{
+			URL:          key,
+			StatusCode:   http.StatusOK,
+			ETag:         etag,
+			LastModified: lastModified,
+			ContentType:  resp.Header.Get("Content-Type"),
+			Body:         body,
+			StoredAt:     time.Now(),
+		}
+		t.cache.store(key, entry)
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
+// path возвращает путь к файлу записи для ссылки
+func (c *HTTPCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name+".json")
+}
+
+// load читает запись из кеша, поврежденные записи игнорируются
+func (c *HTTPCache) load(key string) *cacheEntry {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	if IsSealed(data) {
+		// Запись, зашифрованную другим ключом или без ключа, считаем отсутствующей
+		if c.sealer == nil {
+			return nil
+		}
+		if data, err = c.sealer.Open(data); err != nil {
+			return nil
+		}
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != key {
+		return nil
+	}
+	return &entry
+}
+
+// store атомарно записывает запись в кеш, ошибки записи не прерывают сканирование
+func (c *HTTPCache) store(key string, entry *cacheEntry) {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if c.sealer != nil {
+		if data, err = c.sealer.Seal(data); err != nil {
+			return
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// response строит ответ из записи кеша
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if e.ContentType != "" {
+		header.Set("Content-Type", e.ContentType)
+	}
+	if e.ETag != "" {
+		header.Set("ETag", e.ETag)
+	}
+	if e.LastModified != "" {
+		header.Set("Last-Modified", e.LastModified)
+	}
+	header.Set("X-Cache", "HIT")
+
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultCampaignDistance - наибольшее расстояние Хэмминга между SimHash зеркал одной кампании
+const DefaultCampaignDistance = 6
+
+// shingleSize - число слов в шингле SimHash
+const shingleSize = 3
+
+// Campaign - группа статей с почти одинаковым текстом (перепубликации одной утечки)
+type Campaign struct {
+	ID       string    `json:"id"`       // SimHash первой статьи кампании
+	Articles []Article `json:"articles"` // Статьи в порядке месяца, дня и индекса
+}
+
+// URLs возвращает ссылки всех зеркал кампании
+func (c Campaign) URLs() []string {
+	urls := make([]string, len(c.Articles))
+	for i, article := range c.Articles {
+		urls[i] = article.URL
+	}
+	return urls
+}
+
+// SimHash вычисляет 64-битный SimHash текста по шинглам из трех слов.
+// Регистр, пунктуация и кириллические двойники латинских букв не учитываются.
+func SimHash(text string) string {
+	words := strings.FieldsFunc(string(NormalizeHomoglyphs(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	size := shingleSize
+	if len(words) < size {
+		size = len(words)
+	}
+
+	var weights [64]int
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+size], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+// SimHashDistance возвращает расстояние Хэмминга между двумя SimHash или -1, если значение некорректно
+func SimHashDistance(a, b string) int {
+	x, errA := strconv.ParseUint(a, 16, 64)
+	y, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil {
+		return -1
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+// ClusterCampaigns объединяет статьи в кампании: статьи попадают в одну кампанию, если расстояние
+// между их SimHash не больше maxDistance (транзитивно). Статьи без SimHash (старые экспорты)
+// объединяются только по совпадению ContentHash. Кампании упорядочены по числу зеркал.
+func ClusterCampaigns(articles []Article, maxDistance int) []Campaign {
+	sorted := SortArticles(articles)
+
+	parent := make([]int, len(sorted))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			if sameContent(sorted[i], sorted[j], maxDistance) {
+				// Корнем остается более ранняя статья
+				if ri, rj := find(i), find(j); ri != rj {
+					if ri < rj {
+						parent[rj] = ri
+					} else {
+						parent[ri] = rj
+					}
+				}
+			}
+		}
+	}
+
+	byRoot := make(map[int]int)
+	var campaigns []Campaign
+	for i, article := range sorted {
+		root := find(i)
+		index, ok := byRoot[root]
+		if !ok {
+			index = len(campaigns)
+			byRoot[root] = index
+			id := sorted[root].SimHash
+			if id == "" {
+				id = sorted[root].ContentHash
+			}
+			campaigns = append(campaigns, Campaign{ID: id})
+		}
+		campaigns[index].Articles = append(campaigns[index].Articles, article)
+	}
+
+	sort.SliceStable(campaigns, func(i, j int) bool {
+		return len(campaigns[i].Articles) > len(campaigns[j].Articles)
+	})
+	return campaigns
+}
+
+// sameContent сообщает, что две статьи - копии одного текста
+func sameContent(a, b Article, maxDistance int) bool {
+	if a.ContentHash != "" && a.ContentHash == b.ContentHash {
+		return true
+	}
+	if a.SimHash == "" || b.SimHash == "" {
+		return false
+	}
+	distance := SimHashDistance(a.SimHash, b.SimHash)
+	return distance >= 0 && distance <= maxDistance
+}
@@ -0,0 +1,342 @@
+package parser
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Page - загруженная статья, которую проверяют детекторы
+type Page struct {
+	URL   string
+	Title string
+	Text  string   // Текст статьи
+	Links []string // Ссылки из статьи
+}
+
+// Finding - находка детектора. Секрет в Value замаскирован, исходное значение
+// не сохраняется: для сравнения запусков используется Fingerprint. Детектор заполняет его хешем
+// исходного значения, а Parser заменяет на HMAC с ключом отпечатков: хеш без ключа короткого
+// пароля подбирается словарем, поэтому в экспорты, diff, MISP и syslog попадает только HMAC.
+type Finding struct {
+	Detector    string `json:"detector"`           // Имя детектора (accounts, webhooks, secrets, brand)
+	Type        string `json:"type"`               // Тип находки (email, discord и т.д.)
+	Domain      string `json:"domain"`             // Домен учетной записи или ссылки
+	Owner       string `json:"owner"`              // Домен из списка наблюдения, которому принадлежит находка
+	Match       string `json:"match,omitempty"`    // Найденное написание термина (для brand)
+	Value       string `json:"value"`              // Значение с замаскированным секретом или отрывок текста
+	Fingerprint string `json:"fingerprint"`        // HMAC-SHA256 исходного значения с ключом отпечатков
+	Severity    string `json:"severity,omitempty"` // Важность из правила секретов
+	Source      string `json:"source"`             // Ссылка на статью
+}
+
+// Detector ищет данные на странице и возвращает замаскированные находки
+type Detector interface {
+	Name() string
+	Detect(page Page) []Finding
+}
+
+// NewSecretFinding создает находку для пары идентификатор:секрет, маскируя секрет.
+// Домен определяется по идентификатору, если это адрес электронной почты.
+func NewSecretFinding(detector, findingType, source, identity, secret string) Finding {
+	value := RedactSecret(secret)
+	if identity != "" {
+		value = identity + ":" + value
+	}
+	return Finding{
+		Detector:    detector,
+		Type:        findingType,
+		Domain:      EmailDomain(identity),
+		Value:       value,
+		Fingerprint: fingerprint(findingType, identity, secret),
+		Source:      source,
+	}
+}
+
+// NewURLFinding создает находку для ссылки с секретом (вебхук), маскируя токен
+func NewURLFinding(detector, findingType, source, secretURL string) Finding {
+	return Finding{
+		Detector:    detector,
+		Type:        findingType,
+		Domain:      URLDomain(secretURL),
+		Value:       RedactURL(secretURL),
+		Fingerprint: fingerprint(findingType, "", secretURL),
+		Source:      source,
+	}
+}
+
+// fingerprint вычисляет хеш исходного значения находки; наружу он выдается только с ключом (см. keyFingerprint)
+func fingerprint(findingType, identity, secret string) string {
+	sum := sha256.Sum256([]byte(findingType + "\x00" + identity + "\x00" + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// keyFingerprint заменяет хеш исходного значения на HMAC-SHA256 с ключом отпечатков
+func keyFingerprint(key []byte, digest string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(digest))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MinFingerprintKey - минимальная длина ключа отпечатков находок
+const MinFingerprintKey = 16
+
+// serviceOwners - владельцы находок на доменах сервисов: аккаунты Minecraft и вебхуки Discord, GitHub
+// и Slack не содержат нашего домена. Такие находки сообщаются, если домен сервиса есть в списке
+// наблюдения или включен WithServiceFindings.
+var serviceOwners = map[string]string{
+	"accounts/minecraft": "minecraft.net",
+	"webhooks/discord":   "discord.com",
+	"webhooks/github":    "github.com",
+	"webhooks/slack":     "slack.com",
+}
+
+// ServiceOwner возвращает домен сервиса, которому принадлежат находки детектора указанного типа
+func ServiceOwner(detector, findingType string) (string, bool) {
+	owner, ok := serviceOwners[detector+"/"+findingType]
+	return owner, ok
+}
+
+// Registry - набор доступных детекторов по именам
+type Registry struct {
+	detectors []Detector
+}
+
+// NewRegistry создает реестр из детекторов
+func NewRegistry(detectors ...Detector) (*Registry, error) {
+	r := &Registry{}
+	for _, detector := range detectors {
+		if err := r.Register(detector); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// DefaultRegistry возвращает реестр со встроенными детекторами accounts и webhooks
+func DefaultRegistry() *Registry {
+	return &Registry{detectors: []Detector{accountsDetector{}, webhooksDetector{}}}
+}
+
+// Register добавляет детектор в реестр
+func (r *Registry) Register(detector Detector) error {
+	for _, existing := range r.detectors {
+		if existing.Name() == detector.Name() {
+			return fmt.Errorf("детектор %q уже зарегистрирован", detector.Name())
+		}
+	}
+	r.detectors = append(r.detectors, detector)
+	return nil
+}
+
+// Names возвращает имена детекторов в порядке регистрации
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.detectors))
+	for i, detector := range r.detectors {
+		names[i] = detector.Name()
+	}
+	return names
+}
+
+// Select возвращает детекторы с указанными именами
+func (r *Registry) Select(names ...string) ([]Detector, error) {
+	var selected []Detector
+	for _, name := range names {
+		found := false
+		for _, detector := range r.detectors {
+			if detector.Name() == name {
+				selected = append(selected, detector)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("неизвестный детектор %q, доступны: %s", name, strings.Join(r.Names(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// scopeFindings оставляет находки доменов из списка наблюдения и убирает повторы.
+// Без списка наблюдения находок нет: мы сообщаем только о данных, которые принадлежат нам.
+// С services находки на доменах сервисов (см. serviceOwners) относятся к домену сервиса.
+func scopeFindings(findings []Finding, watchlist *Watchlist, services bool) []Finding {
+	var scoped []Finding
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		owner, ok := watchlist.Contains(finding.Domain)
+		if !ok && services {
+			owner, ok = ServiceOwner(finding.Detector, finding.Type)
+		}
+		if !ok || seen[finding.Fingerprint] {
+			continue
+		}
+		seen[finding.Fingerprint] = true
+		finding.Owner = owner
+		scoped = append(scoped, finding)
+	}
+	return scoped
+}
+
+// loadPage загружает статью для детекторов. Для отсутствующих, пустых и пропущенных страниц возвращается nil.
+func (p *Parser) loadPage(ctx context.Context, url string) (*Page, error) {
+	// Загрузок одновременно не больше, чем соединений с хостом: обработчиков анализа может быть больше
+	if err := p.limiter.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer p.limiter.Release(1)
+
+	// Пропущенные страницы (размер, тип содержимого, редирект) не содержат находок
+	doc, _, _, err := fetchPage(ctx, p.client, url, p.config.MaxBodySize)
+	if doc == nil {
+		return nil, ignoreStatus(err)
+	}
+
+	// Проверка на 404 страницу
+	title := doc.Find("title").Text()
+	if title == "404 Not Found" || title == "Telegraph" || title == "" {
+		return nil, nil
+	}
+
+	// Получаем контент страницы
+	content := doc.Find("article").Text()
+	if content == "" || len(strings.TrimSpace(content)) < 50 {
+		return nil, nil
+	}
+
+	page := &Page{URL: url, Title: title, Text: content}
+	doc.Find("article a[href]").Each(func(_ int, s *goquery.Selection) {
+		if href, ok := s.Attr("href"); ok {
+			page.Links = append(page.Links, href)
+		}
+	})
+	return page, nil
+}
+
+// Analyze загружает статью и запускает на ней детекторы парсера.
+// Возвращаются только находки доменов из списка наблюдения парсера.
+func (p *Parser) Analyze(ctx context.Context, url string) ([]Finding, error) {
+	page, err := p.loadPage(ctx, url)
+	if page == nil {
+		return nil, err
+	}
+	return p.Detect(*page), nil
+}
+
+// Detect запускает детекторы парсера на странице, заменяет отпечатки на HMAC с ключом парсера
+// и применяет список наблюдения
+func (p *Parser) Detect(page Page) []Finding {
+	var findings []Finding
+	for _, detector := range p.detectors {
+		for _, finding := range detector.Detect(page) {
+			finding.Fingerprint = keyFingerprint(p.fingerprintKey, finding.Fingerprint)
+			findings = append(findings, finding)
+		}
+	}
+
+	findings = scopeFindings(findings, p.watchlist, p.services)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Detector < findings[j].Detector
+	})
+	return findings
+}
+
+// Паттерны для поиска учетных данных
+var (
+	emailPassPattern = regexp.MustCompile(`([a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,})[\s:]+([^\s]{3,})`)
+	accountPattern   = regexp.MustCompile(`(account|login|username|user|email)[\s:]+([^\s]+)[\s:]+.*(password|pass|pwd)[\s:]+([^\s]{3,})`)
+	minecraftPattern = regexp.MustCompile(`(minecraft|mc)[\s:]*([^\s:@]+)[\s:]+([^\s]{3,})`)
+
+	// Паттерны для вебхуков
+	discordWebhookPattern = regexp.MustCompile(`https?://(?:(?:canary|ptb)\.)?discord(?:app)?\.com/api/webhooks/([0-9]{17,20})/([A-Za-z0-9\-_]{60,68})`)
+	gitHubWebhookPattern  = regexp.MustCompile(`https?://api\.github\.com/repos/[^/]+/[^/]+/hooks/[0-9]+\?token=([A-Za-z0-9_\-]+)`)
+	slackWebhookPattern   = regexp.MustCompile(`https?://hooks\.slack\.com/services/T[a-zA-Z0-9_]+/B[a-zA-Z0-9_]+/[a-zA-Z0-9_]+`)
+	genericWebhookPattern = regexp.MustCompile(`webhook[s]?[\s:=]+(https?://[a-zA-Z0-9\.\-_/\?=&%]+)`)
+)
+
+// accountsDetector ищет учетные данные: email:pass, аккаунты Minecraft и пары логин/пароль
+type accountsDetector struct{}
+
+// Name возвращает имя детектора
+func (accountsDetector) Name() string {
+	return "accounts"
+}
+
+// Detect ищет учетные данные в тексте статьи
+func (d accountsDetector) Detect(page Page) []Finding {
+	var findings []Finding
+
+	// Поиск email:pass паттернов
+	for _, match := range emailPassPattern.FindAllStringSubmatch(page.Text, -1) {
+		findings = append(findings, NewSecretFinding(d.Name(), "email", page.URL, match[1], match[2]))
+	}
+
+	// Поиск Minecraft аккаунтов: в идентификаторе нет домена, аккаунт относится к домену сервиса
+	for _, match := range minecraftPattern.FindAllStringSubmatch(page.Text, -1) {
+		finding := NewSecretFinding(d.Name(), "minecraft", page.URL, match[2], match[3])
+		finding.Domain, _ = ServiceOwner(d.Name(), "minecraft")
+		findings = append(findings, finding)
+	}
+
+	// Поиск общего формата аккаунтов
+	for _, match := range accountPattern.FindAllStringSubmatch(page.Text, -1) {
+		findings = append(findings, NewSecretFinding(d.Name(), strings.ToLower(match[1]), page.URL, match[2], match[4]))
+	}
+
+	return findings
+}
+
+// webhooksDetector ищет вебхуки Discord, GitHub, Slack и обобщенные ссылки вебхуков.
+// Домен находки - хост ссылки, для вебхуков сервисов это домен сервиса.
+type webhooksDetector struct{}
+
+// Name возвращает имя детектора
+func (webhooksDetector) Name() string {
+	return "webhooks"
+}
+
+// Detect ищет вебхуки в тексте статьи
+func (d webhooksDetector) Detect(page Page) []Finding {
+	var findings []Finding
+	var urls []string
+
+	known := []struct {
+		findingType string
+		pattern     *regexp.Regexp
+	}{
+		{"discord", discordWebhookPattern},
+		{"github", gitHubWebhookPattern},
+		{"slack", slackWebhookPattern},
+	}
+	for _, k := range known {
+		for _, match := range k.pattern.FindAllString(page.Text, -1) {
+			urls = append(urls, match)
+			findings = append(findings, NewURLFinding(d.Name(), k.findingType, page.URL, match))
+		}
+	}
+
+	// Поиск обобщенных вебхуков, не совпадающих с уже найденными
+	for _, match := range genericWebhookPattern.FindAllStringSubmatch(page.Text, -1) {
+		isDuplicate := false
+		for _, url := range urls {
+			if strings.Contains(match[1], url) || strings.Contains(url, match[1]) {
+				isDuplicate = true
+				break
+			}
+		}
+
+		if !isDuplicate {
+			urls = append(urls, match[1])
+			findings = append(findings, NewURLFinding(d.Name(), "generic", page.URL, match[1]))
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"testing"
+)
+
+// detectorPage - статья с учетной записью нашего домена, аккаунтом Minecraft и вебхуком Discord
+var detectorPage = Page{
+	URL: "https://telegra.ph/leak-03-01",
+	Text: "alice@example.com:Hunter2Secret\n" +
+		"minecraft steve:diamond42\n" +
+		"https://discord.com/api/webhooks/123456789012345678/" +
+		"abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789abcdefgh\n",
+}
+
+// detectedTypes возвращает владельцев находок по типам
+func detectedTypes(findings []Finding) map[string]string {
+	types := make(map[string]string)
+	for _, finding := range findings {
+		types[finding.Type] = finding.Owner
+	}
+	return types
+}
+
+// TestDetectServiceFindings проверяет, что находки на доменах сервисов сообщаются только
+// с доменом сервиса в списке наблюдения или с WithServiceFindings
+func TestDetectServiceFindings(t *testing.T) {
+	watchlist := NewWatchlist([]string{"example.com"})
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{"только наши домены", []Option{WithWatchlist(watchlist)},
+			map[string]string{"email": "example.com"}},
+		{"домен сервиса в списке", []Option{WithWatchlist(NewWatchlist([]string{"example.com", "discord.com"}))},
+			map[string]string{"email": "example.com", "discord": "discord.com"}},
+		{"находки сервисов", []Option{WithWatchlist(watchlist), WithServiceFindings()},
+			map[string]string{"email": "example.com", "minecraft": "minecraft.net", "discord": "discord.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectedTypes(New(ParserConfig{}, tt.opts...).Detect(detectorPage))
+			if len(got) != len(tt.want) {
+				t.Fatalf("находки %v, ожидались %v", got, tt.want)
+			}
+			for findingType, owner := range tt.want {
+				if got[findingType] != owner {
+					t.Errorf("%s: владелец %q, ожидался %q", findingType, got[findingType], owner)
+				}
+			}
+		})
+	}
+}
+
+// TestDetectFingerprintKey проверяет, что отпечаток - HMAC с ключом парсера: он совпадает
+// для одного ключа, зависит от ключа и не равен хешу исходного значения без ключа
+func TestDetectFingerprintKey(t *testing.T) {
+	detect := func(key string) string {
+		p := New(ParserConfig{}, WithWatchlist(NewWatchlist([]string{"example.com"})), WithFingerprintKey([]byte(key)))
+		findings := p.Detect(detectorPage)
+		if len(findings) != 1 {
+			t.Fatalf("находок %d, ожидалась 1", len(findings))
+		}
+		return findings[0].Fingerprint
+	}
+
+	first := detect("key-one-0123456789")
+	if first == fingerprint("email", "alice@example.com", "Hunter2Secret") {
+		t.Error("отпечаток совпадает с хешем без ключа")
+	}
+	if detect("key-one-0123456789") != first {
+		t.Error("отпечаток с одним ключом не детерминирован")
+	}
+	if detect("key-two-0123456789") == first {
+		t.Error("отпечаток не зависит от ключа")
+	}
+}
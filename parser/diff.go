@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"sort"
+)
+
+// ArticleChange - статья, содержимое которой изменилось между запусками
+type ArticleChange struct {
+	Old Article `json:"old"`
+	New Article `json:"new"`
+}
+
+// ArticleDiff - разница между двумя наборами статей
+type ArticleDiff struct {
+	Added   []Article       `json:"added"`   // Новые страницы
+	Removed []Article       `json:"removed"` // Исчезнувшие страницы
+	Changed []ArticleChange `json:"changed"` // Страницы с изменившимся хешем содержимого
+}
+
+// DiffArticles сравнивает два набора статей по канонической ссылке
+func DiffArticles(oldArticles, newArticles []Article) ArticleDiff {
+	oldByURL := indexArticles(oldArticles)
+	newByURL := indexArticles(newArticles)
+
+	var diff ArticleDiff
+	for _, article := range SortArticles(newArticles) {
+		old, ok := oldByURL[article.CanonicalURL]
+		if !ok {
+			diff.Added = append(diff.Added, article)
+			continue
+		}
+		if old.ContentHash != "" && article.ContentHash != "" && old.ContentHash != article.ContentHash {
+			diff.Changed = append(diff.Changed, ArticleChange{Old: old, New: article})
+		}
+	}
+
+	for _, article := range SortArticles(oldArticles) {
+		if _, ok := newByURL[article.CanonicalURL]; !ok {
+			diff.Removed = append(diff.Removed, article)
+		}
+	}
+
+	return diff
+}
+
+// indexArticles индексирует статьи по канонической ссылке
+func indexArticles(articles []Article) map[string]Article {
+	index := make(map[string]Article, len(articles))
+	for _, article := range SortArticles(articles) {
+		index[article.CanonicalURL] = article
+	}
+	return index
+}
+
+// FindingsDiff - новые находки, сгруппированные по домену из списка наблюдения
+type FindingsDiff struct {
+	ByDomain map[string][]Finding `json:"by_domain"`
+	Total    int                  `json:"total"`
+}
+
+// Domains возвращает домены с новыми находками в алфавитном порядке
+func (d FindingsDiff) Domains() []string {
+	domains := make([]string, 0, len(d.ByDomain))
+	for domain := range d.ByDomain {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// DiffFindings возвращает находки, которых не было в старом наборе, сравнивая отпечатки.
+// Если список наблюдения не пуст, учитываются только его домены.
+func DiffFindings(oldFindings, newFindings []Finding, watchlist *Watchlist) FindingsDiff {
+	known := make(map[string]bool, len(oldFindings))
+	for _, finding := range oldFindings {
+		known[finding.Fingerprint] = true
+	}
+
+	diff := FindingsDiff{ByDomain: make(map[string][]Finding)}
+	for _, finding := range newFindings {
+		if known[finding.Fingerprint] {
+			continue
+		}
+		known[finding.Fingerprint] = true
+
+		domain := finding.Owner
+		if !watchlist.Empty() {
+			owned, ok := watchlist.Contains(finding.Domain)
+			if !ok {
+				continue
+			}
+			domain = owned
+		}
+
+		diff.ByDomain[domain] = append(diff.ByDomain[domain], finding)
+		diff.Total++
+	}
+
+	return diff
+}
@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+)
+
+// ErrorClass - класс ошибки проверки ссылки
+type ErrorClass string
+
+const (
+	ErrorDNS        ErrorClass = "dns"         // Ошибка разрешения имени
+	ErrorTimeout    ErrorClass = "timeout"     // Превышен таймаут
+	ErrorTLS        ErrorClass = "tls"         // Ошибка TLS рукопожатия или сертификата
+	ErrorHTTPStatus ErrorClass = "http_status" // Неожиданный HTTP статус
+	ErrorParse      ErrorClass = "parse"       // Ошибка разбора HTML
+	ErrorNetwork    ErrorClass = "network"     // Прочие сетевые ошибки (соединение сброшено и т.д.)
+	ErrorOther      ErrorClass = "other"       // Неклассифицированные ошибки
+)
+
+// HTTPStatusError - сервер вернул неожиданный статус (кроме 200 и 404)
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("неожиданный HTTP статус %d", e.StatusCode)
+}
+
+// ParseError - не удалось разобрать HTML страницы
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("ошибка разбора HTML: %v", e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError определяет класс ошибки проверки ссылки
+func ClassifyError(err error) ErrorClass {
+	var statusErr *HTTPStatusError
+	var parseErr *ParseError
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &statusErr):
+		return ErrorHTTPStatus
+	case errors.As(err, &parseErr):
+		return ErrorParse
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return ErrorTLS
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorTimeout
+		}
+		return ErrorNetwork
+	default:
+		return ErrorOther
+	}
+}
+
+// URLError - ошибка проверки конкретной ссылки
+type URLError struct {
+	URL     string     `json:"url"`
+	Class   ErrorClass `json:"class"`
+	Message string     `json:"message"`
+}
+
+// ErrorReport накапливает ошибки проверки ссылок с группировкой по классам
+type ErrorReport struct {
+	mu     sync.Mutex
+	errors []URLError
+	counts map[ErrorClass]int
+}
+
+// NewErrorReport создает пустой отчет об ошибках
+func NewErrorReport() *ErrorReport {
+	return &ErrorReport{counts: make(map[ErrorClass]int)}
+}
+
+// Add классифицирует ошибку и добавляет ее в отчет
+func (r *ErrorReport) Add(url string, err error) {
+	class := ClassifyError(err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errors = append(r.errors, URLError{URL: url, Class: class, Message: err.Error()})
+	r.counts[class]++
+}
+
+// Total возвращает общее количество ошибок
+func (r *ErrorReport) Total() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.errors)
+}
+
+// Counts возвращает количество ошибок по классам
+func (r *ErrorReport) Counts() map[ErrorClass]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[ErrorClass]int, len(r.counts))
+	for class, n := range r.counts {
+		counts[class] = n
+	}
+	return counts
+}
+
+// Classes возвращает классы ошибок по убыванию количества
+func (r *ErrorReport) Classes() []ErrorClass {
+	counts := r.Counts()
+
+	classes := make([]ErrorClass, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if counts[classes[i]] != counts[classes[j]] {
+			return counts[classes[i]] > counts[classes[j]]
+		}
+		return classes[i] < classes[j]
+	})
+	return classes
+}
+
+// Errors возвращает копию списка ошибок
+func (r *ErrorReport) Errors() []URLError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := make([]URLError, len(r.errors))
+	copy(errs, r.errors)
+	return errs
+}
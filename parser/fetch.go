@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultMaxBodySize - максимальный размер страницы по умолчанию (статьи Telegraph значительно меньше)
+const DefaultMaxBodySize int64 = 2 << 20
+
+// Причины пропуска страницы
+const (
+	SkipIgnoreRule      = "ignore_rule"      // страница отброшена правилом игнорирования
+	SkipBodyTooLarge    = "body_too_large"   // размер ответа превышает лимит
+	SkipContentType     = "content_type"     // ответ не является HTML
+	SkipOffsiteRedirect = "offsite_redirect" // редирект на другой сайт
+)
+
+// sameHostRedirect - политика редиректов для клиентов парсера: переходы на другой хост
+// не выполняются, вместо этого возвращается ответ с редиректом
+func sameHostRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("слишком много редиректов")
+	}
+	if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
+// fetchPage загружает страницу и разбирает HTML с проверкой размера, типа содержимого и редиректов.
+// Для отсутствующей страницы возвращается nil без ошибки, для нарушений ограничений - запись о пропуске.
+func fetchPage(ctx context.Context, client *http.Client, link string, maxBodySize int64) (*goquery.Document, *http.Response, *SkipRecord, error) {
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	skip := func(reason, detail string) (*goquery.Document, *http.Response, *SkipRecord, error) {
+		return nil, resp, &SkipRecord{URL: link, Reason: reason, Detail: detail}, nil
+	}
+
+	// Редирект на другой хост остановлен политикой клиента или уже выполнен клиентом без нее
+	if location := resp.Header.Get("Location"); isRedirect(resp.StatusCode) && location != "" {
+		return skip(SkipOffsiteRedirect, location)
+	}
+	if original, err := url.Parse(link); err == nil && !strings.EqualFold(resp.Request.URL.Host, original.Host) {
+		return skip(SkipOffsiteRedirect, resp.Request.URL.String())
+	}
+
+	// Проверка HTTP статуса: 404 означает отсутствие статьи, остальные статусы - ошибка
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, resp, nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp, nil, &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil ||
+		(mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return skip(SkipContentType, contentType)
+	}
+
+	if resp.ContentLength > maxBodySize {
+		return skip(SkipBodyTooLarge, fmt.Sprintf("%d байт", resp.ContentLength))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		return nil, resp, nil, err
+	}
+	if int64(len(body)) > maxBodySize {
+		return skip(SkipBodyTooLarge, fmt.Sprintf("более %d байт", maxBodySize))
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, resp, nil, &ParseError{Err: err}
+	}
+	return doc, resp, nil, nil
+}
+
+// isRedirect сообщает, является ли статус редиректом
+func isRedirect(status int) bool {
+	return status >= 300 && status < 400 && status != http.StatusNotModified
+}
+
+// ignoreStatus отбрасывает ошибки постоянных HTTP статусов 4xx: анализаторы считают такие страницы пустыми.
+// Перегрузка (429) и ошибки сервера (5xx) возвращаются, чтобы недоступность сайта не выглядела как отсутствие находок.
+func ignoreStatus(err error) error {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500 &&
+		statusErr.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Виды правил игнорирования
+const (
+	RuleLiteral = "literal" // подстрока, без учета регистра
+	RuleRegex   = "regex"   // регулярное выражение
+	RuleDomain  = "domain"  // домен ссылки (включая поддомены)
+)
+
+// Области страницы, к которым применяется правило
+const (
+	ScopeTitle = "title"
+	ScopeText  = "text"
+	ScopeLinks = "links"
+	ScopeHTML  = "html" // HTML страницы целиком, включая meta и разметку вне article
+)
+
+// IgnoreRule описывает одно правило фильтрации спама
+type IgnoreRule struct {
+	ID      string   // Идентификатор правила, попадает в журнал пропусков
+	Kind    string   // literal, regex, domain
+	Scopes  []string // title, text, links, html
+	Pattern string
+
+	re *regexp.Regexp
+}
+
+// IgnoreRules - набор правил игнорирования
+type IgnoreRules struct {
+	rules []IgnoreRule
+}
+
+// SkipRecord фиксирует пропущенную страницу: правило игнорирования или нарушение ограничений загрузки
+type SkipRecord struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`            // ignore_rule, body_too_large, content_type, offsite_redirect
+	RuleID string `json:"rule_id,omitempty"` // Для ignore_rule
+	Scope  string `json:"scope,omitempty"`   // Для ignore_rule
+	Detail string `json:"detail,omitempty"`  // Тип содержимого, размер или адрес редиректа
+}
+
+// Label возвращает идентификатор правила или причину пропуска
+func (s SkipRecord) Label() string {
+	if s.RuleID != "" {
+		return s.RuleID
+	}
+	return s.Reason
+}
+
+// urlHostPattern выделяет хосты из ссылок в тексте для доменных правил
+var urlHostPattern = regexp.MustCompile(`(?i)https?://([^\s/?#:"'<>]+)`)
+
+// NewIgnoreRules проверяет правила и компилирует регулярные выражения
+func NewIgnoreRules(rules []IgnoreRule) (*IgnoreRules, error) {
+	seen := make(map[string]bool, len(rules))
+	compiled := make([]IgnoreRule, 0, len(rules))
+
+	for _, rule := range rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("правило без идентификатора: %q", rule.Pattern)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("повторяющийся идентификатор правила: %s", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("правило %s: пустой шаблон", rule.ID)
+		}
+		if len(rule.Scopes) == 0 {
+			return nil, fmt.Errorf("правило %s: не указана область применения", rule.ID)
+		}
+		for _, scope := range rule.Scopes {
+			if scope != ScopeTitle && scope != ScopeText && scope != ScopeLinks && scope != ScopeHTML {
+				return nil, fmt.Errorf("правило %s: неизвестная область %q", rule.ID, scope)
+			}
+		}
+
+		switch rule.Kind {
+		case RuleLiteral:
+			rule.Pattern = strings.ToLower(rule.Pattern)
+		case RuleRegex:
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("правило %s: %w", rule.ID, err)
+			}
+			rule.re = re
+		case RuleDomain:
+			rule.Pattern = strings.TrimPrefix(strings.ToLower(rule.Pattern), ".")
+		default:
+			return nil, fmt.Errorf("правило %s: неизвестный вид %q", rule.ID, rule.Kind)
+		}
+
+		compiled = append(compiled, rule)
+	}
+
+	return &IgnoreRules{rules: compiled}, nil
+}
+
+// LoadIgnoreRules загружает правила из файла.
+// Формат строки: <id> <вид> <области через запятую|all> <шаблон>
+// Пустые строки и строки, начинающиеся с #, пропускаются.
+func LoadIgnoreRules(path string) (*IgnoreRules, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []IgnoreRule
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("%s:%d: ожидается <id> <вид> <области> <шаблон>", path, lineNum)
+		}
+
+		// Шаблон - остаток строки, он может содержать пробелы
+		pattern := line
+		for _, field := range fields[:3] {
+			pattern = strings.TrimSpace(strings.TrimPrefix(pattern, field))
+		}
+
+		scopes := strings.Split(fields[2], ",")
+		if fields[2] == "all" {
+			scopes = []string{ScopeTitle, ScopeText, ScopeLinks, ScopeHTML}
+		}
+
+		rules = append(rules, IgnoreRule{
+			ID:      fields[0],
+			Kind:    fields[1],
+			Scopes:  scopes,
+			Pattern: pattern,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	ignoreRules, err := NewIgnoreRules(rules)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return ignoreRules, nil
+}
+
+// DefaultIgnoreRules строит правила из встроенного списка слов. Как и до правил из файла,
+// слова ищутся во всем HTML страницы, а не только в заголовке, тексте и ссылках статьи.
+func DefaultIgnoreRules() *IgnoreRules {
+	rules := make([]IgnoreRule, 0, len(builtinIgnoreList))
+	for i, word := range builtinIgnoreList {
+		rules = append(rules, IgnoreRule{
+			ID:      fmt.Sprintf("builtin-%d", i+1),
+			Kind:    RuleLiteral,
+			Scopes:  []string{ScopeHTML},
+			Pattern: word,
+		})
+	}
+
+	ignoreRules, err := NewIgnoreRules(rules)
+	if err != nil {
+		panic(err)
+	}
+
+	return ignoreRules
+}
+
+// Len возвращает количество правил
+func (r *IgnoreRules) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.rules)
+}
+
+// Match проверяет страницу и возвращает идентификатор и область первого сработавшего правила
+func (r *IgnoreRules) Match(title, text, html string, links []string) (ruleID string, scope string, matched bool) {
+	if r == nil {
+		return "", "", false
+	}
+
+	for _, rule := range r.rules {
+		for _, scope := range rule.Scopes {
+			var ok bool
+			switch scope {
+			case ScopeTitle:
+				ok = rule.matchText(title)
+			case ScopeText:
+				ok = rule.matchText(text)
+			case ScopeHTML:
+				ok = rule.matchText(html)
+			case ScopeLinks:
+				for _, link := range links {
+					if rule.matchLink(link) {
+						ok = true
+						break
+					}
+				}
+			}
+
+			if ok {
+				return rule.ID, scope, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// matchText применяет правило к заголовку, тексту статьи или HTML страницы
+func (rule *IgnoreRule) matchText(text string) bool {
+	switch rule.Kind {
+	case RuleLiteral:
+		return strings.Contains(strings.ToLower(text), rule.Pattern)
+	case RuleRegex:
+		return rule.re.MatchString(text)
+	case RuleDomain:
+		for _, match := range urlHostPattern.FindAllStringSubmatch(text, -1) {
+			if rule.matchHost(match[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchLink применяет правило к адресу ссылки
+func (rule *IgnoreRule) matchLink(link string) bool {
+	if rule.Kind != RuleDomain {
+		return rule.matchText(link)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return rule.matchHost(u.Hostname())
+}
+
+// matchHost сравнивает хост с доменом правила с учетом поддоменов
+func (rule *IgnoreRule) matchHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host == rule.Pattern || strings.HasSuffix(host, "."+rule.Pattern)
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// publishedTimeLayouts - форматы article:published_time (Telegraph пишет смещение без двоеточия)
+var publishedTimeLayouts = []string{
+	"2006-01-02T15:04:05-0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+}
+
+// PageMetadata - метаданные страницы Telegraph
+type PageMetadata struct {
+	Author        string     // article:author или подпись статьи
+	AuthorURL     string     // Ссылка автора из подписи статьи
+	PublishedAt   *time.Time // article:published_time
+	OutboundLinks []string   // Ссылки из статьи на другие сайты, без повторов
+}
+
+// ExtractMetadata извлекает автора, время публикации и внешние ссылки из страницы.
+// pageURL используется для разрешения относительных ссылок и определения внешних.
+func ExtractMetadata(doc *goquery.Document, pageURL *url.URL) PageMetadata {
+	var meta PageMetadata
+
+	meta.Author = strings.TrimSpace(metaContent(doc, "article:author"))
+
+	// Подпись статьи: <address><a rel="author" href="...">Имя</a></address>
+	authorLink := doc.Find(`article address a[rel="author"], address a[rel="author"]`).First()
+	if href, ok := authorLink.Attr("href"); ok {
+		if resolved := resolveLink(pageURL, href); resolved != nil {
+			meta.AuthorURL = resolved.String()
+		}
+	}
+	if meta.Author == "" {
+		meta.Author = strings.TrimSpace(authorLink.Text())
+	}
+
+	if published := strings.TrimSpace(metaContent(doc, "article:published_time")); published != "" {
+		for _, layout := range publishedTimeLayouts {
+			if t, err := time.Parse(layout, published); err == nil {
+				t = t.UTC()
+				meta.PublishedAt = &t
+				break
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	// Подпись автора не считается ссылкой из текста статьи
+	doc.Find("article a[href]").Not("address a").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		link := resolveLink(pageURL, href)
+		if link == nil || (link.Scheme != "http" && link.Scheme != "https") {
+			return
+		}
+		if pageURL != nil && strings.EqualFold(link.Hostname(), pageURL.Hostname()) {
+			return
+		}
+		if value := link.String(); !seen[value] {
+			seen[value] = true
+			meta.OutboundLinks = append(meta.OutboundLinks, value)
+		}
+	})
+
+	return meta
+}
+
+// metaContent возвращает значение <meta property="..."> или <meta name="...">
+func metaContent(doc *goquery.Document, property string) string {
+	selection := doc.Find(`meta[property="` + property + `"], meta[name="` + property + `"]`).First()
+	content, _ := selection.Attr("content")
+	return content
+}
+
+// resolveLink разрешает ссылку относительно страницы
+func resolveLink(pageURL *url.URL, href string) *url.URL {
+	link, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return nil
+	}
+	if pageURL != nil {
+		link = pageURL.ResolveReference(link)
+	}
+	return link
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics получает события сканирования для мониторинга
+type Metrics interface {
+	ObserveRequest(status string, duration time.Duration) // HTTP запрос: статус ("200", "404", "error") и длительность
+	IncRetry()                                            // Повторная попытка после ошибки
+	IncPageFound()                                        // Найдена статья
+	IncPageIgnored(ruleID string)                         // Страница отброшена правилом игнорирования
+	IncFinding(kind string)                               // Найдены данные (email, discord и т.д.)
+}
+
+// metricsTransport измеряет статус и длительность HTTP запросов
+type metricsTransport struct {
+	base    http.RoundTripper
+	metrics Metrics
+}
+
+// InstrumentTransport оборачивает транспорт для сбора метрик запросов.
+// Если metrics равен nil, транспорт возвращается без изменений.
+func InstrumentTransport(base http.RoundTripper, metrics Metrics) http.RoundTripper {
+	if metrics == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &metricsTransport{base: base, metrics: metrics}
+}
+
+// RoundTrip выполняет запрос и сообщает о его результате
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.ObserveRequest(status, time.Since(start))
+
+	return resp, err
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mispTagNamespace - пространство имен машинных тегов событий MISP
+const mispTagNamespace = "telegraph-finder"
+
+// MISPEvent - событие MISP в формате REST API (/events/add)
+type MISPEvent struct {
+	Event MISPEventBody `json:"Event"`
+}
+
+// MISPEventBody - свойства события MISP
+type MISPEventBody struct {
+	UUID          string          `json:"uuid"`
+	Info          string          `json:"info"`
+	Date          string          `json:"date"`
+	ThreatLevelID string          `json:"threat_level_id"` // 1 - высокий, 2 - средний, 3 - низкий, 4 - не определен
+	Analysis      string          `json:"analysis"`        // 0 - начальный, 1 - в работе, 2 - завершен
+	Distribution  string          `json:"distribution"`    // 0 - только наша организация
+	Attribute     []MISPAttribute `json:"Attribute"`
+	Tag           []MISPTag       `json:"Tag"`
+}
+
+// MISPAttribute - атрибут события MISP
+type MISPAttribute struct {
+	UUID     string `json:"uuid"`
+	Type     string `json:"type"`
+	Category string `json:"category"`
+	Value    string `json:"value"`
+	ToIDS    bool   `json:"to_ids"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// MISPTag - тег события MISP
+type MISPTag struct {
+	Name string `json:"name"`
+}
+
+// MISPOptions - параметры события MISP
+type MISPOptions struct {
+	Info string    // Заголовок события
+	TLP  string    // white, green, amber, red; пусто - без тега TLP
+	Now  time.Time // Дата события
+}
+
+// NewMISPEvent строит событие MISP из проанализированных статей и находок: каждая страница - атрибут url,
+// хеш ее текста - атрибут sha256, число находок по детекторам и типам - теги события.
+// Значения находок в событие не попадают, даже замаскированные.
+func NewMISPEvent(articles []Article, findings []Finding, opts MISPOptions) MISPEvent {
+	info := opts.Info
+	if info == "" {
+		info = "Telegraph: утечки данных наших доменов"
+	}
+
+	// Число находок по странице для комментария атрибута url
+	perPage := make(map[string]int)
+	for _, finding := range findings {
+		perPage[finding.Source]++
+	}
+
+	event := MISPEventBody{
+		Info:          info,
+		Date:          opts.Now.UTC().Format("2006-01-02"),
+		ThreatLevelID: mispThreatLevel(findings),
+		Analysis:      "0",
+		Distribution:  "0",
+		Attribute:     []MISPAttribute{},
+		Tag:           []MISPTag{},
+	}
+
+	for _, article := range SortArticles(articles) {
+		comment := article.Title
+		if count := perPage[article.URL]; count > 0 {
+			comment = fmt.Sprintf("%s (находок: %d)", article.Title, count)
+		}
+		event.Attribute = append(event.Attribute, MISPAttribute{
+			UUID:     uuidV5(stixNamespace, "misp-url\x00"+article.URL),
+			Type:     "url",
+			Category: "External analysis",
+			Value:    article.URL,
+			Comment:  comment,
+		})
+		if article.ContentHash != "" {
+			event.Attribute = append(event.Attribute, MISPAttribute{
+				UUID:     uuidV5(stixNamespace, "misp-sha256\x00"+article.URL+"\x00"+article.ContentHash),
+				Type:     "sha256",
+				Category: "External analysis",
+				Value:    article.ContentHash,
+				Comment:  "SHA-256 текста " + article.URL,
+			})
+		}
+	}
+
+	if opts.TLP != "" {
+		event.Tag = append(event.Tag, MISPTag{Name: "tlp:" + opts.TLP})
+	}
+	for _, tag := range findingCountTags(findings) {
+		event.Tag = append(event.Tag, MISPTag{Name: tag})
+	}
+
+	ids := make([]string, len(event.Attribute))
+	for i, attribute := range event.Attribute {
+		ids[i] = attribute.UUID
+	}
+	event.UUID = uuidV5(stixNamespace, "misp-event\x00"+event.Date+"\x00"+strings.Join(ids, ","))
+
+	return MISPEvent{Event: event}
+}
+
+// findingCountTags возвращает машинные теги с числом находок по детекторам, типам и нашим доменам
+func findingCountTags(findings []Finding) []string {
+	counts := make(map[string]int)
+	for _, finding := range findings {
+		counts["detector:"+finding.Detector]++
+		counts["type:"+finding.Type]++
+		if finding.Owner != "" {
+			counts["owner:"+finding.Owner]++
+		}
+		if finding.Severity != "" {
+			counts["severity:"+finding.Severity]++
+		}
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := make([]string, 0, len(keys)+1)
+	tags = append(tags, fmt.Sprintf("%s:findings=\"%d\"", mispTagNamespace, len(findings)))
+	for _, key := range keys {
+		kind, value, _ := strings.Cut(key, ":")
+		tags = append(tags, fmt.Sprintf("%s:%s=\"%s %d\"", mispTagNamespace, kind, value, counts[key]))
+	}
+	return tags
+}
+
+// PostMISPEvent отправляет событие в MISP (POST <baseURL>/events/add) с ключом API в заголовке Authorization
+func PostMISPEvent(ctx context.Context, client *http.Client, baseURL, apiKey string, event MISPEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/events/add"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Ответ дочитывается до конца, чтобы соединение вернулось в пул keep-alive
+	defer io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Ответ MISP с текстом ошибки ограничен, чтобы не заполнить журнал
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s", &HTTPStatusError{StatusCode: resp.StatusCode}, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// mispThreatLevel возвращает уровень угрозы MISP по самой важной находке.
+// Находки без важности (аккаунты, вебхуки, упоминания) считаются средними.
+func mispThreatLevel(findings []Finding) string {
+	level := "4"
+	for _, finding := range findings {
+		current := "2"
+		switch finding.Severity {
+		case SeverityCritical, SeverityHigh:
+			current = "1"
+		case SeverityLow:
+			current = "3"
+		}
+		if current < level {
+			level = current
+		}
+	}
+	return level
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mispRequest - запрос, полученный тестовым сервером MISP
+type mispRequest struct {
+	method        string
+	path          string
+	authorization string
+	contentType   string
+	body          []byte
+}
+
+// newMISPServer запускает тестовый сервер MISP, который отвечает статусом status и сохраняет последний запрос
+func newMISPServer(t *testing.T, status int, response string) (*httptest.Server, *mispRequest) {
+	t.Helper()
+	received := &mispRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*received = mispRequest{
+			method:        r.Method,
+			path:          r.URL.Path,
+			authorization: r.Header.Get("Authorization"),
+			contentType:   r.Header.Get("Content-Type"),
+			body:          body,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// TestPostMISPEvent проверяет адрес, заголовки и форму события, отправленного в MISP
+func TestPostMISPEvent(t *testing.T) {
+	articles, _, findings := stixFixture()
+	event := NewMISPEvent(articles, findings, MISPOptions{TLP: "amber", Now: time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)})
+
+	server, received := newMISPServer(t, http.StatusOK, `{"Event":{"id":"1"}}`)
+	// Завершающая косая черта в адресе MISP не удваивается
+	if err := PostMISPEvent(context.Background(), server.Client(), server.URL+"/", "secret-api-key", event); err != nil {
+		t.Fatal(err)
+	}
+
+	if received.method != http.MethodPost || received.path != "/events/add" {
+		t.Errorf("запрос %s %s, ожидался POST /events/add", received.method, received.path)
+	}
+	if received.authorization != "secret-api-key" {
+		t.Errorf("Authorization = %q", received.authorization)
+	}
+	if received.contentType != "application/json" {
+		t.Errorf("Content-Type = %q", received.contentType)
+	}
+
+	var sent struct {
+		Event struct {
+			UUID      string `json:"uuid"`
+			Info      string `json:"info"`
+			Date      string `json:"date"`
+			Attribute []struct {
+				UUID  string `json:"uuid"`
+				Type  string `json:"type"`
+				Value string `json:"value"`
+			} `json:"Attribute"`
+			Tag []struct {
+				Name string `json:"name"`
+			} `json:"Tag"`
+		} `json:"Event"`
+	}
+	if err := json.Unmarshal(received.body, &sent); err != nil {
+		t.Fatalf("тело запроса не является событием MISP: %v", err)
+	}
+	if sent.Event.UUID != event.Event.UUID || sent.Event.Info == "" || sent.Event.Date != "2024-03-05" {
+		t.Errorf("событие uuid=%q info=%q date=%q", sent.Event.UUID, sent.Event.Info, sent.Event.Date)
+	}
+
+	types := make(map[string]int)
+	for _, attribute := range sent.Event.Attribute {
+		types[attribute.Type]++
+		if attribute.UUID == "" || attribute.Value == "" {
+			t.Errorf("атрибут без uuid или значения: %+v", attribute)
+		}
+	}
+	// Три страницы и хеши их текстов
+	if types["url"] != 3 || types["sha256"] != 3 || len(types) != 2 {
+		t.Errorf("атрибуты по типам: %v", types)
+	}
+
+	tags := make(map[string]bool)
+	for _, tag := range sent.Event.Tag {
+		tags[tag.Name] = true
+	}
+	for _, want := range []string{"tlp:amber", `telegraph-finder:findings="4"`, `telegraph-finder:detector="accounts 2"`} {
+		if !tags[want] {
+			t.Errorf("нет тега %s среди %v", want, tags)
+		}
+	}
+
+	// Значения находок в событие не попадают, даже замаскированные
+	for _, finding := range findings {
+		if strings.Contains(string(received.body), finding.Value) || strings.Contains(string(received.body), finding.Fingerprint) {
+			t.Errorf("событие содержит значение находки %q", finding.Value)
+		}
+	}
+}
+
+// TestPostMISPEventStatus проверяет, что ответ MISP кроме 2xx возвращается ошибкой со статусом и началом ответа
+func TestPostMISPEventStatus(t *testing.T) {
+	response := `{"message":"Authentication failed.","details":"` + strings.Repeat("x", 8<<10) + `"}`
+	server, _ := newMISPServer(t, http.StatusForbidden, response)
+
+	err := PostMISPEvent(context.Background(), server.Client(), server.URL, "wrong-key", NewMISPEvent(nil, nil, MISPOptions{}))
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("ошибка %v, ожидался HTTPStatusError 403", err)
+	}
+	if !strings.Contains(err.Error(), "Authentication failed.") {
+		t.Errorf("в ошибке нет ответа MISP: %v", err)
+	}
+	if len(err.Error()) > 600 {
+		t.Errorf("ответ MISP в ошибке не ограничен: %d байт", len(err.Error()))
+	}
+}
@@ -2,12 +2,10 @@ package parser
 
 import (
 	"context"
-	"fmt"
+	"crypto/rand"
+	"log/slog"
 	"net/http"
-	"regexp"
 	"strings"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -17,14 +15,26 @@ import (
 
 // ParserConfig содержит конфигурацию парсера
 type ParserConfig struct {
-	MaxConcurrentRequests   int64         // Максимальное количество одновременных HTTP запросов
-	RequestTimeout          time.Duration // Таймаут HTTP запросов
-	RetryCount              int           // Количество повторных попыток при ошибке
-	RetryDelay              time.Duration // Задержка между повторными попытками
-	DelayBetweenRequests    time.Duration // Задержка между запросами (для избежания блокировки)
-	YearsToSearch           []int         // Годы для поиска
-	MonthsToSearch          []int         // Месяцы для поиска (1-12, если пусто - все месяцы)
-	IncludeTranslitVariants bool          // Включать ли транслитерированные варианты запроса
+	MaxConcurrentRequests   int64            // Максимальное количество одновременных HTTP запросов (и соединений с хостом)
+	RequestTimeout          time.Duration    // Таймаут HTTP запросов
+	RetryCount              int              // Количество повторных попыток при ошибке
+	RetryDelay              time.Duration    // Задержка между повторными попытками
+	DelayBetweenRequests    time.Duration    // Задержка между запросами (для избежания блокировки)
+	YearsToSearch           []int            // Годы для поиска
+	MonthsToSearch          []int            // Месяцы для поиска (1-12, если пусто - все месяцы)
+	IncludeTranslitVariants bool             // Включать ли транслитерированные варианты запроса
+	FirstIndex              int              // Первый проверяемый индекс за день (0 - 1, ссылка без индекса)
+	LastIndex               int              // Последний проверяемый индекс за день (0 - 30)
+	IgnoreRules             *IgnoreRules     // Правила игнорирования спама (nil - встроенный список)
+	OnSkip                  func(SkipRecord) // Вызывается для каждой пропущенной страницы
+	ProgressInterval        time.Duration    // Минимальный интервал между событиями прогресса
+	Metrics                 Metrics          // Сбор метрик сканирования (nil - отключен)
+	Cache                   *HTTPCache       // HTTP кеш на диске (nil - отключен)
+	MaxBodySize             int64            // Максимальный размер страницы в байтах (0 - DefaultMaxBodySize)
+	UserAgent               string           // User-Agent запросов (пусто - DefaultUserAgent)
+	DialTimeout             time.Duration    // Таймаут установки TCP соединения
+	TLSHandshakeTimeout     time.Duration    // Таймаут TLS рукопожатия
+	ResponseHeaderTimeout   time.Duration    // Таймаут ожидания заголовков ответа
 }
 
 // DefaultConfig возвращает конфигурацию парсера по умолчанию
@@ -38,39 +48,123 @@ func DefaultConfig() ParserConfig {
 		YearsToSearch:           []int{}, // Не используется
 		MonthsToSearch:          []int{},
 		IncludeTranslitVariants: true,
+		IgnoreRules:             DefaultIgnoreRules(),
+		ProgressInterval:        200 * time.Millisecond,
+		MaxBodySize:             DefaultMaxBodySize,
+		UserAgent:               DefaultUserAgent,
+		DialTimeout:             5 * time.Second,
+		TLSHandshakeTimeout:     5 * time.Second,
+		ResponseHeaderTimeout:   10 * time.Second,
 	}
 }
 
-// AccountPattern содержит паттерны для поиска учетных данных
-var (
-	EmailPassPattern = regexp.MustCompile(`([a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,})[\s:]+([^\s]{3,})`)
-	AccountPattern   = regexp.MustCompile(`(account|login|username|user|email)[\s:]+([^\s]+)[\s:]+.*(password|pass|pwd)[\s:]+([^\s]{3,})`)
-	MinecraftPattern = regexp.MustCompile(`(minecraft|mc)[\s:]*([^\s:@]+)[\s:]+([^\s]{3,})`)
-
-	// Паттерны для вебхуков
-	DiscordWebhookPattern = regexp.MustCompile(`https?://(?:(?:canary|ptb)\.)?discord(?:app)?\.com/api/webhooks/([0-9]{17,20})/([A-Za-z0-9\-_]{60,68})`)
-	GitHubWebhookPattern  = regexp.MustCompile(`https?://api\.github\.com/repos/[^/]+/[^/]+/hooks/[0-9]+\?token=([A-Za-z0-9_\-]+)`)
-	SlackWebhookPattern   = regexp.MustCompile(`https?://hooks\.slack\.com/services/T[a-zA-Z0-9_]+/B[a-zA-Z0-9_]+/[a-zA-Z0-9_]+`)
-	GenericWebhookPattern = regexp.MustCompile(`webhook[s]?[\s:=]+(https?://[a-zA-Z0-9\.\-_/\?=&%]+)`)
-)
+// Parser - экземпляр парсера со своей конфигурацией, HTTP клиентом, правилами игнорирования,
+// ограничителем параллельности и журналом. Несколько экземпляров с разной конфигурацией
+// могут работать в одном процессе независимо друг от друга.
+type Parser struct {
+	config         ParserConfig
+	client         *http.Client
+	rules          *IgnoreRules
+	limiter        *semaphore.Weighted
+	logger         *slog.Logger
+	detectors      []Detector
+	watchlist      *Watchlist
+	services       bool   // Сообщать о находках на доменах сервисов
+	fingerprintKey []byte // Ключ HMAC для отпечатков находок
+}
+
+// Option настраивает Parser при создании
+type Option func(*Parser)
+
+// WithClient задает HTTP клиент вместо созданного по конфигурации
+func WithClient(client *http.Client) Option {
+	return func(p *Parser) {
+		p.client = client
+	}
+}
+
+// WithLimiter задает ограничитель одновременных проверок ссылок, например общий для нескольких парсеров
+func WithLimiter(limiter *semaphore.Weighted) Option {
+	return func(p *Parser) {
+		p.limiter = limiter
+	}
+}
+
+// WithLogger задает журнал парсера (по умолчанию slog.Default())
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Parser) {
+		p.logger = logger
+	}
+}
+
+// WithDetectors задает детекторы для Analyze (по умолчанию все из DefaultRegistry)
+func WithDetectors(detectors ...Detector) Option {
+	return func(p *Parser) {
+		p.detectors = detectors
+	}
+}
+
+// WithWatchlist задает список наблюдения: Analyze возвращает только находки его доменов
+func WithWatchlist(watchlist *Watchlist) Option {
+	return func(p *Parser) {
+		p.watchlist = watchlist
+	}
+}
+
+// WithServiceFindings включает находки на доменах сервисов (аккаунты Minecraft, вебхуки Discord, GitHub и Slack),
+// даже если домена сервиса нет в списке наблюдения
+func WithServiceFindings() Option {
+	return func(p *Parser) {
+		p.services = true
+	}
+}
+
+// WithFingerprintKey задает ключ HMAC для отпечатков находок. С одним ключом отпечатки повторной
+// находки совпадают в разных запусках; без него парсер использует случайный ключ.
+func WithFingerprintKey(key []byte) Option {
+	return func(p *Parser) {
+		p.fingerprintKey = key
+	}
+}
+
+// New создает парсер с конфигурацией config
+func New(config ParserConfig, opts ...Option) *Parser {
+	p := &Parser{config: config}
+	for _, opt := range opts {
+		opt(p)
+	}
 
-// WebhookData представляет найденный вебхук
-type WebhookData struct {
-	Type   string // discord, github, slack, generic
-	URL    string
-	Source string // URL источника
+	if p.client == nil {
+		p.client = NewClient(config)
+	}
+	if p.limiter == nil {
+		p.limiter = semaphore.NewWeighted(max(config.MaxConcurrentRequests, 1))
+	}
+	if p.logger == nil {
+		p.logger = slog.Default()
+	}
+	if p.detectors == nil {
+		p.detectors = DefaultRegistry().detectors
+	}
+	if p.fingerprintKey == nil {
+		p.fingerprintKey = make([]byte, 32)
+		rand.Read(p.fingerprintKey)
+	}
+
+	p.rules = config.IgnoreRules
+	if p.rules == nil {
+		p.rules = DefaultIgnoreRules()
+	}
+	return p
 }
 
-// Account представляет найденные учетные данные
-type Account struct {
-	Type     string // тип аккаунта (minecraft, email и т.д.)
-	Username string
-	Password string
-	Source   string // URL источника
+// Config возвращает конфигурацию парсера
+func (p *Parser) Config() ParserConfig {
+	return p.config
 }
 
-// IgnoreList содержит слова, которые игнорируются в результатах поиска
-var IgnoreList = []string{
+// builtinIgnoreList содержит слова, которые игнорируются в результатах поиска
+var builtinIgnoreList = []string{
 	"https://t.me/SLlV_INTIM_BOT",
 	"https://t.me/+YztOEovieQIzZjY8",
 	"free vpn infinite time",
@@ -104,523 +198,208 @@ func Translit(text string) string {
 	return result
 }
 
-// FindArticle проверяет, существует ли статья по заданному URL и не содержит ли она игнорируемых слов
-func FindArticle(client *http.Client, url string) (string, error) {
-	resp, err := client.Get(url)
-	if err != nil {
+// FindArticle проверяет, существует ли статья по заданному URL и не содержит ли она игнорируемых слов.
+// Возвращает строку "title - url" или пустую строку, если статья не найдена или пропущена.
+func (p *Parser) FindArticle(ctx context.Context, url string) (string, error) {
+	article, _, err := p.CheckArticle(ctx, url)
+	if article == nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	// Проверка HTTP статуса
-	if resp.StatusCode != 200 {
-		return "", nil
-	}
+	return article.String(), err
+}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return "", err
+// CheckArticle проверяет статью по заданному URL с правилами игнорирования парсера.
+// Если статья не найдена, возвращается nil. Если страница отброшена правилом,
+// возвращается запись о пропуске с идентификатором правила. Ответы больше maxBodySize,
+// не-HTML ответы и редиректы на другой сайт также возвращаются как пропуск, а не ошибка.
+func (p *Parser) CheckArticle(ctx context.Context, url string) (*Article, *SkipRecord, error) {
+	doc, resp, skip, err := fetchPage(ctx, p.client, url, p.config.MaxBodySize)
+	if doc == nil {
+		return nil, skip, err
 	}
 
 	// Проверка на 404 страницу (Telegraph возвращает 200 для некоторых несуществующих страниц)
 	title := doc.Find("title").Text()
 	if title == "404 Not Found" || title == "Telegraph" || title == "" {
-		return "", nil
+		return nil, nil, nil
 	}
 
 	// Проверка содержимого страницы
 	content := doc.Find("article").Text()
 	if content == "" || len(strings.TrimSpace(content)) < 50 {
 		// Страница пустая или слишком короткая
-		return "", nil
+		return nil, nil, nil
 	}
 
-	// Проверка на наличие игнорируемых слов
-	html, err := doc.Html()
-	if err != nil {
-		return "", err
-	}
-
-	for _, ignoreWord := range IgnoreList {
-		if strings.Contains(html, ignoreWord) {
-			return "", nil
+	// Проверка правил игнорирования по заголовку, тексту, ссылкам и HTML страницы
+	var links []string
+	doc.Find("article a[href]").Each(func(_ int, s *goquery.Selection) {
+		if href, ok := s.Attr("href"); ok {
+			links = append(links, href)
 		}
-	}
+	})
 
-	// Формируем результат с заголовком статьи
-	result := fmt.Sprintf("%s - %s", title, url)
-	return result, nil
+	html, _ := doc.Html()
+	if ruleID, scope, ok := p.rules.Match(title, content, html, links); ok {
+		return nil, &SkipRecord{URL: url, Reason: SkipIgnoreRule, RuleID: ruleID, Scope: scope}, nil
+	}
+
+	// Формируем результат с заголовком статьи, итоговой ссылкой после редиректов и метаданными
+	meta := ExtractMetadata(doc, resp.Request.URL)
+	article := &Article{
+		Title:         title,
+		URL:           url,
+		CanonicalURL:  CanonicalURL(resp.Request.URL.String()),
+		ContentHash:   ContentHash(content),
+		SimHash:       SimHash(content),
+		Author:        meta.Author,
+		AuthorURL:     meta.AuthorURL,
+		PublishedAt:   meta.PublishedAt,
+		OutboundLinks: meta.OutboundLinks,
+	}
+	return article, nil, nil
 }
 
-// ExtractAccounts извлекает учетные данные из контента страницы
-func ExtractAccounts(url string) ([]Account, error) {
-	var accounts []Account
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, nil
-	}
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	content := doc.Find("article").Text()
-
-	// Поиск email:pass паттернов
-	emailMatches := EmailPassPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range emailMatches {
-		if len(match) >= 3 {
-			accounts = append(accounts, Account{
-				Type:     "email",
-				Username: match[1],
-				Password: match[2],
-				Source:   url,
-			})
+// checkWithRetry проверяет статью с повторными попытками при ошибке
+// и сообщает о пропуске через config.OnSkip
+func (p *Parser) checkWithRetry(ctx context.Context, url string, tracker *ProgressTracker) (*Article, error) {
+	var result *Article
+	var skip *SkipRecord
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		result, skip, err = p.CheckArticle(ctx, url)
+		if err == nil || attempt >= p.config.RetryCount || ctx.Err() != nil {
+			break
 		}
-	}
 
-	// Поиск Minecraft аккаунтов
-	mcMatches := MinecraftPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range mcMatches {
-		if len(match) >= 4 {
-			accounts = append(accounts, Account{
-				Type:     "minecraft",
-				Username: match[2],
-				Password: match[3],
-				Source:   url,
-			})
+		p.logger.Debug("Повторная проверка ссылки", "url", url, "attempt", attempt+1, "error", err)
+		tracker.AddRetry()
+		if p.config.Metrics != nil {
+			p.config.Metrics.IncRetry()
 		}
-	}
-
-	// Поиск общего формата аккаунтов
-	accMatches := AccountPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range accMatches {
-		if len(match) >= 5 {
-			accounts = append(accounts, Account{
-				Type:     strings.ToLower(match[1]),
-				Username: match[2],
-				Password: match[4],
-				Source:   url,
-			})
+		select {
+		case <-time.After(p.config.RetryDelay):
+		case <-ctx.Done():
+			return nil, err
 		}
 	}
 
-	return accounts, nil
-}
-
-// FindAccountsInArticle ищет учетные данные в статье
-func FindAccountsInArticle(client *http.Client, url string) ([]Account, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
+	if skip != nil {
+		p.logger.Debug("Страница пропущена", "url", url, "reason", skip.Reason, "rule", skip.RuleID)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, nil
+	if skip != nil && p.config.Metrics != nil {
+		p.config.Metrics.IncPageIgnored(skip.Label())
 	}
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// Проверка на 404 страницу
-	title := doc.Find("title").Text()
-	if title == "404 Not Found" || title == "Telegraph" || title == "" {
-		return nil, nil
-	}
-
-	// Получаем контент страницы
-	content := doc.Find("article").Text()
-	if content == "" || len(strings.TrimSpace(content)) < 50 {
-		return nil, nil
+	if skip != nil && p.config.OnSkip != nil {
+		p.config.OnSkip(*skip)
 	}
+	return result, err
+}
 
-	var accounts []Account
+// scanDay проверяет все ссылки за указанный день и передает найденные статьи и ошибки в emit
+// config - конфигурация задачи: конфигурация парсера с настройками запроса пакета
+func (p *Parser) scanDay(ctx context.Context, config ParserConfig, query string, month, day int,
+	tracker *ProgressTracker, emit func(Event)) error {
+	g, gctx := errgroup.WithContext(ctx)
 
-	// Поиск email:pass паттернов
-	emailMatches := EmailPassPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range emailMatches {
-		if len(match) >= 3 {
-			accounts = append(accounts, Account{
-				Type:     "email",
-				Username: match[1],
-				Password: match[2],
-				Source:   url,
-			})
+	// checkURL проверяет одну ссылку и сообщает о результате
+	checkURL := func(url string, index int) {
+		// Одновременных проверок не больше, чем соединений с хостом
+		if err := p.limiter.Acquire(ctx, 1); err != nil {
+			return
 		}
-	}
-
-	// Поиск Minecraft аккаунтов
-	mcMatches := MinecraftPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range mcMatches {
-		if len(match) >= 4 {
-			accounts = append(accounts, Account{
-				Type:     "minecraft",
-				Username: match[2],
-				Password: match[3],
-				Source:   url,
-			})
+		article, err := p.checkWithRetry(ctx, url, tracker)
+		p.limiter.Release(1)
+		tracker.AddProbed()
+		if err != nil {
+			tracker.AddError()
+			emit(Event{Kind: EventError, URL: url, Err: err})
+		} else if article != nil {
+			tracker.AddHit()
+			if p.config.Metrics != nil {
+				p.config.Metrics.IncPageFound()
+			}
+			article.Query = query
+			article.Month = month
+			article.Day = day
+			article.Index = index
+			emit(Event{Kind: EventArticle, URL: url, Article: *article})
 		}
 	}
 
-	// Поиск общего формата аккаунтов
-	accMatches := AccountPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range accMatches {
-		if len(match) >= 5 {
-			accounts = append(accounts, Account{
-				Type:     strings.ToLower(match[1]),
-				Username: match[2],
-				Password: match[4],
-				Source:   url,
-			})
-		}
-	}
-
-	return accounts, nil
-}
-
-// FindArticlesForDay ищет статьи за указанный день месяца
-func FindArticlesForDay(ctx context.Context, client *http.Client, query, month, day string, year int) ([]string, error) {
-	var results []string
-	var mu sync.Mutex
-	g, gctx := errgroup.WithContext(ctx)
+	first, last := indexRange(config)
 
 	// Проверяем статью без индекса
-	g.Go(func() error {
-		select {
-		case <-gctx.Done():
-			return gctx.Err()
-		default:
-			// продолжаем выполнение
-		}
-
-		// В Telegraph URL обычно не содержит год
-		url := fmt.Sprintf("https://telegra.ph/%s-%s-%s", query, month, day)
-		if article, err := FindArticle(client, url); err == nil && article != "" {
-			mu.Lock()
-			results = append(results, article)
-			mu.Unlock()
-		}
+	if first <= 1 {
+		g.Go(func() error {
+			select {
+			case <-gctx.Done():
+				return gctx.Err()
+			default:
+				// продолжаем выполнение
+			}
 
-		return nil
-	})
+			// В Telegraph URL обычно не содержит год
+			checkURL(articleURL(query, month, day, 1), 1)
+			return nil
+		})
+	}
 
-	// Проверяем статьи с индексами от 2 до 30
-	for i := 2; i <= 30; i++ {
+	// Проверяем статьи с индексами (по умолчанию от 2 до 30)
+	for i := max(first, 2); i <= last; i++ {
 		index := i // Создаем локальную переменную для горутины
 		g.Go(func() error {
-			time.Sleep(time.Duration(index-1) * 100 * time.Millisecond) // Задержка для предотвращения блокировки
+			time.Sleep(time.Duration(index-first) * indexStagger) // Задержка для предотвращения блокировки
 
 			// В Telegraph URL обычно содержит только индекс, но не год
-			url := fmt.Sprintf("https://telegra.ph/%s-%s-%s-%d", query, month, day, index)
-			if article, err := FindArticle(client, url); err == nil && article != "" {
-				mu.Lock()
-				results = append(results, article)
-				mu.Unlock()
-			}
-
+			checkURL(articleURL(query, month, day, index), index)
 			return nil
 		})
 	}
 
-	if err := g.Wait(); err != nil {
-		return nil, err
-	}
-
-	return results, nil
+	return g.Wait()
 }
 
-// FindArticlesForMonth ищет статьи за указанный месяц
-// Параметр year не используется в формировании URL, но сохраняется для совместимости
-func FindArticlesForMonth(ctx context.Context, client *http.Client, query string, month, year int) ([]string, error) {
-	var results []string
-	var mu sync.Mutex
+// scanMonth проверяет каждый день месяца и передает события в emit
+func (p *Parser) scanMonth(ctx context.Context, config ParserConfig, query string, month int,
+	tracker *ProgressTracker, emit func(Event)) error {
 	g := errgroup.Group{}
 
-	monthStr := fmt.Sprintf("%02d", month)
-
 	// Проверяем каждый день месяца
-	for day := 1; day <= 31; day++ {
-		dayStr := fmt.Sprintf("%02d", day)
+	for day := 1; day <= daysPerMonth; day++ {
 		day := day // Создаем локальную копию для горутины
 		g.Go(func() error {
-			time.Sleep(time.Duration(day-1) * 50 * time.Millisecond) // Небольшая задержка
-			// Параметр year передается, но не используется в URL
-			articles, err := FindArticlesForDay(ctx, client, query, monthStr, dayStr, year)
-			if err != nil {
-				return err
-			}
-
-			if len(articles) > 0 {
-				mu.Lock()
-				results = append(results, articles...)
-				mu.Unlock()
-			}
-			return nil
+			time.Sleep(time.Duration(day-1) * dayStagger) // Небольшая задержка
+			return p.scanDay(ctx, config, query, month, day, tracker, emit)
 		})
 	}
 
-	if err := g.Wait(); err != nil {
-		return nil, err
-	}
-
-	return results, nil
+	return g.Wait()
 }
 
-// FindArticlesWithConfig ищет все статьи по запросу с использованием указанной конфигурации
-// Параметр годов сохраняется для совместимости, но не используется
-func FindArticlesWithConfig(ctx context.Context, query string, config ParserConfig, progressCallback func(int, int)) ([]string, error) {
-	queries := []string{query}
-
-	// Если включена опция транслитерации, добавляем транслитерированный вариант
-	if config.IncludeTranslitVariants {
-		translitQuery := Translit(query)
-		if translitQuery != query {
-			queries = append(queries, translitQuery)
-		}
-	}
-
-	// Создаем HTTP клиент с настроенным таймаутом
-	client := &http.Client{
-		Timeout: config.RequestTimeout,
-	}
-
-	var results []string
-	var mu sync.Mutex
-
-	// Атомарный счетчик для отслеживания прогресса
-	var processedTasks int32
-
-	// Если месяцы не указаны, используем все (1-12)
-	months := config.MonthsToSearch
-	if len(months) == 0 {
-		months = make([]int, 12)
-		for i := 0; i < 12; i++ {
-			months[i] = i + 1
-		}
-	}
-
-	// Год не влияет на URL в Telegraph, но нужен как параметр для совместимости
-	currentYear := time.Now().Year()
-
-	// Вычисляем общее количество задач (месяц*запрос)
-	totalTasks := len(months) * len(queries)
-
-	// Создаем семафор для ограничения количества параллельных запросов
-	sem := semaphore.NewWeighted(config.MaxConcurrentRequests)
-
-	// Запускаем горутину для регулярного обновления прогресса
-	if progressCallback != nil {
-		go func() {
-			ticker := time.NewTicker(100 * time.Millisecond)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-ticker.C:
-					current := atomic.LoadInt32(&processedTasks)
-					progressCallback(int(current), totalTasks)
-				case <-ctx.Done():
-					return
-				}
+// FindArticles ищет все статьи по запросу.
+// Результат очищен от дубликатов и отсортирован по месяцу, дню и индексу.
+func (p *Parser) FindArticles(ctx context.Context, query string, progressCallback func(Progress)) ([]Article, error) {
+	var results []Article
+
+	// Собираем статьи из потока событий, ошибки отдельных ссылок пропускаем
+	for event := range p.Search(ctx, query) {
+		switch event.Kind {
+		case EventArticle:
+			results = append(results, event.Article)
+		case EventProgress:
+			if progressCallback != nil {
+				progressCallback(event.Progress)
 			}
-		}()
-	}
-
-	// Создаем группу ошибок для синхронизации горутин
-	g, gctx := errgroup.WithContext(ctx)
-
-	// Для каждого запроса и месяца создаем отдельную задачу
-	for _, searchQuery := range queries {
-		for _, month := range months {
-			// Создаем локальные копии переменных для горутин
-			q := searchQuery
-			m := month
-
-			g.Go(func() error {
-				// Приобретаем блокировку семафора
-				if err := sem.Acquire(gctx, 1); err != nil {
-					return err
-				}
-				defer sem.Release(1)
-
-				// Вносим задержку для предотвращения блокировки сервера
-				time.Sleep(config.DelayBetweenRequests)
-
-				// Выполняем поиск для конкретного месяца
-				// Год передается как параметр, но не используется в URL
-				monthArticles, err := FindArticlesForMonth(gctx, client, q, m, currentYear)
-
-				// Увеличиваем счетчик обработанных задач
-				atomic.AddInt32(&processedTasks, 1)
-
-				if err != nil {
-					return err
-				}
-
-				// Если найдены статьи, добавляем их в общий список
-				if len(monthArticles) > 0 {
-					mu.Lock()
-					results = append(results, monthArticles...)
-					mu.Unlock()
-				}
-
-				return nil
-			})
 		}
 	}
 
-	// Ожидаем завершения всех горутин
-	if err := g.Wait(); err != nil {
+	results = SortArticles(results)
+
+	if err := ctx.Err(); err != nil {
 		return results, err
 	}
 
 	return results, nil
 }
-
-// FindArticles вызывает FindArticlesWithConfig с конфигурацией по умолчанию
-func FindArticles(ctx context.Context, query string, progressCallback func(int, int)) ([]string, error) {
-	return FindArticlesWithConfig(ctx, query, DefaultConfig(), progressCallback)
-}
-
-// FindArticlesForSpecificURL проверяет конкретную ссылку на Telegraph
-func FindArticlesForSpecificURL(ctx context.Context, url string) (string, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	return FindArticle(client, url)
-}
-
-// FindAccountsForSpecificURL проверяет конкретную ссылку на наличие учетных данных
-func FindAccountsForSpecificURL(ctx context.Context, url string) ([]Account, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	return FindAccountsInArticle(client, url)
-}
-
-// FindWebhooksInArticle ищет вебхуки в статье
-func FindWebhooksInArticle(client *http.Client, url string) ([]WebhookData, error) {
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, nil
-	}
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// Проверка на 404 страницу
-	title := doc.Find("title").Text()
-	if title == "404 Not Found" || title == "Telegraph" || title == "" {
-		return nil, nil
-	}
-
-	// Получаем контент страницы
-	content := doc.Find("article").Text()
-	if content == "" || len(strings.TrimSpace(content)) < 50 {
-		return nil, nil
-	}
-
-	var webhooks []WebhookData
-
-	// Поиск Discord вебхуков
-	discordMatches := DiscordWebhookPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range discordMatches {
-		if len(match) > 0 {
-			webhooks = append(webhooks, WebhookData{
-				Type:   "discord",
-				URL:    match[0],
-				Source: url,
-			})
-		}
-	}
-
-	// Поиск GitHub вебхуков
-	githubMatches := GitHubWebhookPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range githubMatches {
-		if len(match) > 0 {
-			webhooks = append(webhooks, WebhookData{
-				Type:   "github",
-				URL:    match[0],
-				Source: url,
-			})
-		}
-	}
-
-	// Поиск Slack вебхуков
-	slackMatches := SlackWebhookPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range slackMatches {
-		if len(match) > 0 {
-			webhooks = append(webhooks, WebhookData{
-				Type:   "slack",
-				URL:    match[0],
-				Source: url,
-			})
-		}
-	}
-
-	// Поиск обобщенных вебхуков
-	genericMatches := GenericWebhookPattern.FindAllStringSubmatch(content, -1)
-	for _, match := range genericMatches {
-		if len(match) > 1 {
-			// Проверяем, что вебхук не совпадает с уже найденными
-			isDuplicate := false
-			for _, wh := range webhooks {
-				if strings.Contains(match[1], wh.URL) || strings.Contains(wh.URL, match[1]) {
-					isDuplicate = true
-					break
-				}
-			}
-
-			if !isDuplicate {
-				webhooks = append(webhooks, WebhookData{
-					Type:   "generic",
-					URL:    match[1],
-					Source: url,
-				})
-			}
-		}
-	}
-
-	return webhooks, nil
-}
-
-// ExtractWebhooks извлекает вебхуки из контента страницы
-func ExtractWebhooks(url string) ([]WebhookData, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	return FindWebhooksInArticle(client, url)
-}
-
-// FindWebhooksForSpecificURL проверяет конкретную ссылку на наличие вебхуков
-func FindWebhooksForSpecificURL(ctx context.Context, url string) ([]WebhookData, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	return FindWebhooksInArticle(client, url)
-}
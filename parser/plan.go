@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Границы перебора ссылок за месяц
+const (
+	daysPerMonth = 31 // Дни месяца 1-31
+	maxIndex     = 30 // Ссылка без индекса считается первой, далее индексы 2..30
+)
+
+// Задержки, с которыми сканирование запускает проверки дней и индексов
+const (
+	dayStagger   = 50 * time.Millisecond
+	indexStagger = 100 * time.Millisecond
+)
+
+// indexRange возвращает диапазон индексов из конфигурации (по умолчанию 1..30)
+func indexRange(config ParserConfig) (first, last int) {
+	first, last = config.FirstIndex, config.LastIndex
+	if first < 1 {
+		first = 1
+	}
+	if last < 1 {
+		last = maxIndex
+	}
+	return first, last
+}
+
+// articleURL формирует ссылку Telegraph для запроса, даты и индекса.
+// В Telegraph ссылка не содержит год, первая статья за день идет без индекса.
+func articleURL(query string, month, day, index int) string {
+	if index <= 1 {
+		return fmt.Sprintf("https://telegra.ph/%s-%02d-%02d", query, month, day)
+	}
+	return fmt.Sprintf("https://telegra.ph/%s-%02d-%02d-%d", query, month, day, index)
+}
+
+// Plan - план сканирования: варианты запроса, месяцы, диапазоны и оценка нагрузки.
+// Построение плана не выполняет сетевых запросов.
+type Plan struct {
+	Queries    []string      // Варианты запроса (исходный и транслитерированный)
+	Months     []int         // Месяцы для поиска
+	Days       int           // Дней в каждом месяце
+	FirstIndex int           // Первый индекс (ссылка без индекса)
+	LastIndex  int           // Последний индекс
+	Requests   int64         // Количество ссылок без учета повторных попыток
+	MaxRetries int64         // Дополнительные запросы, если все попытки неудачны
+	Rate       float64       // Оценка скорости, запросов в секунду
+	ETA        time.Duration // Оценка длительности сканирования
+}
+
+// NewPlan строит план сканирования для запроса. latency - ожидаемое время ответа,
+// по нему, числу одновременных запросов и задержкам запуска проверок оценивается скорость.
+func NewPlan(query string, config ParserConfig, latency time.Duration) Plan {
+	plan := Plan{
+		Queries: searchQueries(query, config),
+		Months:  searchMonths(config),
+		Days:    daysPerMonth,
+	}
+	plan.FirstIndex, plan.LastIndex = indexRange(config)
+
+	plan.Requests = plan.tasks() * daysPerMonth * int64(plan.LastIndex-plan.FirstIndex+1)
+	plan.MaxRetries = plan.Requests * int64(config.RetryCount)
+	plan.Rate, plan.ETA = estimate(config, plan.tasks(), plan.Requests, plan.taskDuration(config, latency), latency)
+	return plan
+}
+
+// tasks возвращает число задач запрос×месяц плана
+func (p Plan) tasks() int64 {
+	return int64(len(p.Queries) * len(p.Months))
+}
+
+// taskDuration возвращает наименьшую длительность задачи: задержки запуска проверок и время ответа
+func (p Plan) taskDuration(config ParserConfig, latency time.Duration) time.Duration {
+	return config.DelayBetweenRequests + (daysPerMonth-1)*dayStagger + time.Duration(p.LastIndex-p.FirstIndex)*indexStagger + latency
+}
+
+// estimate оценивает скорость и длительность сканирования requests ссылок в tasks задачах,
+// каждая из которых длится не меньше taskMin
+func estimate(config ParserConfig, tasks, requests int64, taskMin, latency time.Duration) (float64, time.Duration) {
+	// Одновременно выполняется до MaxConcurrentRequests проверок, но не больше, чем ссылок в плане
+	concurrency := config.MaxConcurrentRequests
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if latency <= 0 {
+		latency = time.Millisecond
+	}
+	if tasks < 1 || requests < 1 {
+		return 0, 0
+	}
+	rate := float64(min(requests, concurrency)) / latency.Seconds()
+	eta := time.Duration(float64(requests) / rate * float64(time.Second))
+
+	// Задачи запрос×месяц выполняются волнами, каждая не короче задержек запуска проверок
+	waves := int64(math.Ceil(float64(tasks) / float64(concurrency)))
+	if minETA := time.Duration(waves) * taskMin; minETA > eta {
+		eta = minETA
+		rate = float64(requests) / minETA.Seconds()
+	}
+	return rate, eta
+}
+
+// BatchPlan - план пакетного поиска: план каждого запроса пакета с его настройками
+// и общая оценка нагрузки. Задачи всех запросов выполняются с общим ограничением параллельности.
+type BatchPlan struct {
+	Entries    []Plan        // Планы запросов в порядке пакета
+	Requests   int64         // Количество ссылок всех запросов без учета повторных попыток
+	MaxRetries int64         // Дополнительные запросы, если все попытки неудачны
+	Rate       float64       // Оценка скорости, запросов в секунду
+	ETA        time.Duration // Оценка длительности всего пакета
+}
+
+// NewBatchPlan строит план пакетного поиска без сетевых запросов
+func NewBatchPlan(batch []BatchQuery, config ParserConfig, latency time.Duration) BatchPlan {
+	var plan BatchPlan
+	var tasks int64
+	var taskMin time.Duration
+	for _, entry := range batch {
+		entryConfig := entry.apply(config)
+		entryPlan := NewPlan(entry.Query, entryConfig, latency)
+
+		plan.Entries = append(plan.Entries, entryPlan)
+		plan.Requests += entryPlan.Requests
+		plan.MaxRetries += entryPlan.MaxRetries
+		tasks += entryPlan.tasks()
+		taskMin = max(taskMin, entryPlan.taskDuration(entryConfig, latency))
+	}
+
+	plan.Rate, plan.ETA = estimate(config, tasks, plan.Requests, taskMin, latency)
+	return plan
+}
+
+// URLs возвращает ссылки плана в порядке запрос, месяц, день, индекс.
+// При limit > 0 возвращается не больше limit ссылок, равномерно выбранных из всего плана.
+func (p Plan) URLs(limit int) []string {
+	step := int64(1)
+	if limit > 0 && p.Requests > int64(limit) {
+		step = p.Requests / int64(limit)
+	}
+
+	var urls []string
+	var n int64
+	for _, query := range p.Queries {
+		for _, month := range p.Months {
+			for day := 1; day <= p.Days; day++ {
+				for index := p.FirstIndex; index <= p.LastIndex; index++ {
+					if n%step == 0 && (limit <= 0 || len(urls) < limit) {
+						urls = append(urls, articleURL(query, month, day, index))
+					}
+					n++
+				}
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progress - снимок состояния сканирования или анализа
+type Progress struct {
+	Probed  int64         // Проверено ссылок
+	Total   int64         // Всего ссылок
+	Hits    int64         // Найдено статей (или статей с находками при анализе)
+	Errors  int64         // Ошибок проверки
+	Retries int64         // Повторных попыток
+	Rate    float64       // Текущая скорость, ссылок в секунду
+	Elapsed time.Duration // Прошло времени с начала
+	ETA     time.Duration // Оценка оставшегося времени
+	Final   bool          // Последний снимок после завершения работы
+}
+
+// Percent возвращает процент выполнения
+func (p Progress) Percent() int {
+	if p.Total <= 0 {
+		return 0
+	}
+	return int(float64(p.Probed) / float64(p.Total) * 100)
+}
+
+// ProgressTracker считает прогресс и сообщает о нем не чаще заданного интервала
+type ProgressTracker struct {
+	total   int64
+	probed  atomic.Int64
+	hits    atomic.Int64
+	errors  atomic.Int64
+	retries atomic.Int64
+	start   time.Time
+
+	report   func(Progress)
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewProgressTracker создает счетчик прогресса и запускает периодическую отправку снимков.
+// Если report равен nil, снимки не отправляются, но счетчики продолжают работать.
+func NewProgressTracker(total int64, interval time.Duration, report func(Progress)) *ProgressTracker {
+	t := &ProgressTracker{
+		total:  total,
+		start:  time.Now(),
+		report: report,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if interval <= 0 {
+		interval = 200 * time.Millisecond
+	}
+
+	go t.run(interval)
+	return t
+}
+
+// run отправляет снимки по таймеру до вызова Stop
+func (t *ProgressTracker) run(interval time.Duration) {
+	defer close(t.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if t.report != nil {
+				t.report(t.Snapshot())
+			}
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// Stop останавливает отправку и передает финальный снимок. Повторные вызовы безопасны.
+func (t *ProgressTracker) Stop() {
+	if t == nil {
+		return
+	}
+
+	t.stopOnce.Do(func() {
+		close(t.stop)
+		<-t.done
+
+		if t.report != nil {
+			final := t.Snapshot()
+			final.Final = true
+			t.report(final)
+		}
+	})
+}
+
+// AddProbed увеличивает счетчик проверенных ссылок
+func (t *ProgressTracker) AddProbed() {
+	if t != nil {
+		t.probed.Add(1)
+	}
+}
+
+// AddHit увеличивает счетчик найденных статей
+func (t *ProgressTracker) AddHit() {
+	if t != nil {
+		t.hits.Add(1)
+	}
+}
+
+// AddError увеличивает счетчик ошибок
+func (t *ProgressTracker) AddError() {
+	if t != nil {
+		t.errors.Add(1)
+	}
+}
+
+// AddRetry увеличивает счетчик повторных попыток
+func (t *ProgressTracker) AddRetry() {
+	if t != nil {
+		t.retries.Add(1)
+	}
+}
+
+// Snapshot возвращает текущее состояние с расчетом скорости и оставшегося времени
+func (t *ProgressTracker) Snapshot() Progress {
+	if t == nil {
+		return Progress{}
+	}
+
+	p := Progress{
+		Probed:  t.probed.Load(),
+		Total:   t.total,
+		Hits:    t.hits.Load(),
+		Errors:  t.errors.Load(),
+		Retries: t.retries.Load(),
+		Elapsed: time.Since(t.start),
+	}
+
+	if seconds := p.Elapsed.Seconds(); seconds > 0 {
+		p.Rate = float64(p.Probed) / seconds
+	}
+	if p.Rate > 0 && p.Total > p.Probed {
+		p.ETA = time.Duration(float64(p.Total-p.Probed) / p.Rate * float64(time.Second))
+	}
+
+	return p
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactSecret маскирует секрет, оставляя первые два символа.
+// Длина маски фиксирована, чтобы не раскрывать длину секрета.
+func RedactSecret(secret string) string {
+	runes := []rune(secret)
+	if len(runes) <= 4 {
+		return "****"
+	}
+	return string(runes[:2]) + "****"
+}
+
+// RedactURL маскирует последний сегмент пути и параметры ссылки (токен вебхука)
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return RedactSecret(rawURL)
+	}
+
+	if u.RawQuery != "" {
+		u.RawQuery = "****"
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if i := strings.LastIndex(path, "/"); i >= 0 && i < len(path)-1 {
+		u.Path = path[:i+1] + RedactSecret(path[i+1:])
+	}
+
+	// Не экранируем маску в выводе
+	redacted := u.String()
+	return strings.ReplaceAll(redacted, "%2A", "*")
+}
+
+// EmailDomain возвращает домен адреса электронной почты в нижнем регистре
+func EmailDomain(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 || at == len(address)-1 {
+		return ""
+	}
+	return strings.ToLower(address[at+1:])
+}
+
+// URLDomain возвращает хост ссылки в нижнем регистре
+func URLDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// Паттерны для маскировки секретов в отрывках текста
+var (
+	secretAssignmentPattern = regexp.MustCompile(`(?i)\b(password|passwd|pass|pwd|пароль|token|secret|api[_-]?key)(\s*[:=]\s*)(\S+)`)
+	secretTokenPattern      = regexp.MustCompile(`[A-Za-z0-9_\-+/=]{24,}`)
+)
+
+// RedactText маскирует секреты в произвольном тексте: ссылки вебхуков, пары email:пароль,
+// значения после password:, token= и т.п., а также длинные случайные токены
+func RedactText(text string) string {
+	for _, pattern := range []*regexp.Regexp{discordWebhookPattern, gitHubWebhookPattern, slackWebhookPattern} {
+		text = pattern.ReplaceAllStringFunc(text, RedactURL)
+	}
+
+	text = secretAssignmentPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := secretAssignmentPattern.FindStringSubmatch(match)
+		return parts[1] + parts[2] + RedactSecret(parts[3])
+	})
+
+	text = emailPassPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := emailPassPattern.FindStringSubmatch(match)
+		return strings.TrimSuffix(match, parts[2]) + RedactSecret(parts[2])
+	})
+
+	// Длинные строки без цифр (слова через дефис, домены) не похожи на токены
+	return secretTokenPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if !strings.ContainsAny(match, "0123456789") || shannonEntropy(match) < 3.5 {
+			return match
+		}
+		return RedactSecret(match)
+	})
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Article представляет найденную статью
+type Article struct {
+	Title        string `json:"title"`
+	URL          string `json:"url"`           // Проверенная ссылка
+	CanonicalURL string `json:"canonical_url"` // Итоговая ссылка после редиректов в каноническом виде
+	Query        string `json:"query"`         // Вариант запроса, по которому найдена статья
+	Keyword      string `json:"keyword"`       // Исходный запрос (из пакетного файла или командной строки)
+	Month        int    `json:"month"`
+	Day          int    `json:"day"`
+	Index        int    `json:"index"`             // 1 для ссылки без индекса
+	ContentHash  string `json:"content_hash"`      // SHA-256 текста статьи
+	SimHash      string `json:"simhash,omitempty"` // SimHash текста для поиска перепубликаций
+
+	Author        string     `json:"author,omitempty"`         // Автор из article:author или подписи
+	AuthorURL     string     `json:"author_url,omitempty"`     // Ссылка автора
+	PublishedAt   *time.Time `json:"published_at,omitempty"`   // Время публикации из article:published_time
+	OutboundLinks []string   `json:"outbound_links,omitempty"` // Ссылки на другие сайты
+}
+
+// String возвращает статью в формате "title - url"
+func (a Article) String() string {
+	return fmt.Sprintf("%s - %s", a.Title, a.URL)
+}
+
+// ContentHash вычисляет SHA-256 текста статьи без начальных и конечных пробелов
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CanonicalURL приводит ссылку к каноническому виду: нижний регистр схемы и хоста,
+// декодированный путь без завершающего слэша, без параметров и фрагмента
+func CanonicalURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if path == "" {
+		path = "/"
+	}
+
+	return fmt.Sprintf("%s://%s%s", strings.ToLower(u.Scheme), strings.ToLower(u.Host), path)
+}
+
+// SortArticles удаляет дубликаты по канонической ссылке и сортирует статьи
+// по месяцу, дню и индексу. Из дубликатов остается первый по порядку сортировки.
+func SortArticles(articles []Article) []Article {
+	sorted := make([]Article, len(articles))
+	copy(sorted, articles)
+
+	for i := range sorted {
+		if sorted[i].CanonicalURL == "" {
+			sorted[i].CanonicalURL = CanonicalURL(sorted[i].URL)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		if a.CanonicalURL != b.CanonicalURL {
+			return a.CanonicalURL < b.CanonicalURL
+		}
+		return a.Query < b.Query
+	})
+
+	seen := make(map[string]bool, len(sorted))
+	result := sorted[:0]
+	for _, article := range sorted {
+		if seen[article.CanonicalURL] {
+			continue
+		}
+		seen[article.CanonicalURL] = true
+		result = append(result, article)
+	}
+
+	return result
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sealMagic - заголовок зашифрованных файлов
+var sealMagic = []byte("TFSEAL1\n")
+
+// SealKeySize - размер ключа AES-256 в байтах
+const SealKeySize = 32
+
+// ErrSealed - файл зашифрован, а ключ не задан
+var ErrSealed = errors.New("файл зашифрован, нужен ключ")
+
+// Sealer шифрует файлы AES-256-GCM. Формат файла: заголовок, nonce, шифротекст с тегом.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer создает шифратор с ключом из 32 байт
+func NewSealer(key []byte) (*Sealer, error) {
+	if len(key) != SealKeySize {
+		return nil, fmt.Errorf("ключ должен содержать %d байта, получено %d", SealKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// LoadSealer загружает ключ из файла или, если файл не указан, из переменной окружения.
+// Ключ записывается в hex (64 символа) или base64. Без ключа возвращается nil.
+func LoadSealer(keyFile, keyEnv string) (*Sealer, error) {
+	var encoded string
+	switch {
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	case keyEnv != "":
+		encoded = os.Getenv(keyEnv)
+	}
+
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		if keyFile != "" {
+			return nil, fmt.Errorf("%s: пустой ключ", keyFile)
+		}
+		return nil, nil
+	}
+
+	key, err := decodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return NewSealer(key)
+}
+
+// decodeKey декодирует ключ из hex или base64
+func decodeKey(encoded string) ([]byte, error) {
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == SealKeySize {
+		return key, nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(encoded); err == nil && len(key) == SealKeySize {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("ключ должен быть %d байтами в hex или base64 (например, openssl rand -hex %d)", SealKeySize, SealKeySize)
+}
+
+// IsSealed сообщает, что данные зашифрованы Sealer
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, sealMagic)
+}
+
+// Seal шифрует данные со случайным nonce
+func (s *Sealer) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 0, len(sealMagic)+len(nonce)+len(plaintext)+s.aead.Overhead())
+	sealed = append(sealed, sealMagic...)
+	sealed = append(sealed, nonce...)
+	// Заголовок входит в проверяемые данные, чтобы его нельзя было подменить
+	return s.aead.Seal(sealed, nonce, plaintext, sealMagic), nil
+}
+
+// Open расшифровывает данные и проверяет их целостность
+func (s *Sealer) Open(sealed []byte) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, errors.New("данные не зашифрованы")
+	}
+
+	data := sealed[len(sealMagic):]
+	if len(data) < s.aead.NonceSize()+s.aead.Overhead() {
+		return nil, errors.New("зашифрованные данные повреждены")
+	}
+
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, sealMagic)
+	if err != nil {
+		return nil, errors.New("неверный ключ или данные повреждены")
+	}
+	return plaintext, nil
+}
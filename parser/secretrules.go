@@ -0,0 +1,306 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Уровни важности правил секретов
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// checksumValidators - проверки контрольной суммы, доступные правилам секретов
+var checksumValidators = map[string]func(secret string) bool{
+	"luhn":         validLuhn,
+	"crc32-hex":    validCRC32Hex,
+	"crc32-base62": validCRC32Base62,
+}
+
+// SecretRule описывает формат внутреннего секрета (например, API ключа наших сервисов)
+type SecretRule struct {
+	ID       string  // Идентификатор правила, становится типом находки
+	Owner    string  // Наш домен, которому принадлежат секреты этого формата
+	Severity string  // low, medium, high, critical
+	Checksum string  // Имя проверки контрольной суммы, пусто - без проверки
+	Entropy  float64 // Минимальная энтропия секрета, бит на символ
+	Pattern  string  // Регулярное выражение; группа secret, если есть, выделяет секрет
+
+	re *regexp.Regexp
+}
+
+// SecretRules - детектор секретов по правилам из файла
+type SecretRules struct {
+	rules []SecretRule
+}
+
+// NewSecretRules проверяет правила и компилирует регулярные выражения
+func NewSecretRules(rules []SecretRule) (*SecretRules, error) {
+	seen := make(map[string]bool, len(rules))
+	compiled := make([]SecretRule, 0, len(rules))
+
+	for _, rule := range rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("правило без идентификатора: %q", rule.Pattern)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("повторяющийся идентификатор правила: %s", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		rule.Owner = strings.TrimPrefix(strings.ToLower(rule.Owner), ".")
+		if rule.Owner == "" {
+			return nil, fmt.Errorf("правило %s: не указан домен владельца", rule.ID)
+		}
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("правило %s: пустой шаблон", rule.ID)
+		}
+
+		switch rule.Severity {
+		case "":
+			rule.Severity = SeverityMedium
+		case SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+		default:
+			return nil, fmt.Errorf("правило %s: неизвестная важность %q", rule.ID, rule.Severity)
+		}
+
+		if rule.Checksum != "" && checksumValidators[rule.Checksum] == nil {
+			return nil, fmt.Errorf("правило %s: неизвестная проверка контрольной суммы %q", rule.ID, rule.Checksum)
+		}
+		if rule.Entropy < 0 || math.IsNaN(rule.Entropy) {
+			return nil, fmt.Errorf("правило %s: некорректный порог энтропии %v", rule.ID, rule.Entropy)
+		}
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("правило %s: %w", rule.ID, err)
+		}
+		rule.re = re
+
+		compiled = append(compiled, rule)
+	}
+
+	return &SecretRules{rules: compiled}, nil
+}
+
+// LoadSecretRules загружает правила секретов из файла.
+// Формат строки: <id> <домен> [severity=...] [checksum=...] [entropy=...] <регулярное выражение>
+// Пустые строки и строки, начинающиеся с #, пропускаются.
+func LoadSecretRules(path string) (*SecretRules, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []SecretRule
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule, err := parseSecretRuleLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	secretRules, err := NewSecretRules(rules)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return secretRules, nil
+}
+
+// parseSecretRuleLine разбирает строку файла правил секретов
+func parseSecretRuleLine(line string) (SecretRule, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return SecretRule{}, fmt.Errorf("ожидается <id> <домен> [параметры] <регулярное выражение>")
+	}
+
+	rule := SecretRule{ID: fields[0], Owner: fields[1]}
+	consumed := fields[:2]
+
+	// Параметры key=value идут до шаблона, шаблон - остаток строки
+	for _, field := range fields[2:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			break
+		}
+
+		switch key {
+		case "severity":
+			rule.Severity = value
+		case "checksum":
+			rule.Checksum = value
+		case "entropy":
+			entropy, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return SecretRule{}, fmt.Errorf("некорректный порог энтропии %q", value)
+			}
+			rule.Entropy = entropy
+		default:
+			// Не параметр: шаблон начинается здесь
+			ok = false
+		}
+		if !ok {
+			break
+		}
+		consumed = append(consumed, field)
+	}
+
+	pattern := line
+	for _, field := range consumed {
+		pattern = strings.TrimSpace(strings.TrimPrefix(pattern, field))
+	}
+	rule.Pattern = pattern
+	if rule.Pattern == "" {
+		return SecretRule{}, fmt.Errorf("правило %s: пустой шаблон", rule.ID)
+	}
+
+	return rule, nil
+}
+
+// Len возвращает количество правил
+func (r *SecretRules) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.rules)
+}
+
+// Name возвращает имя детектора
+func (r *SecretRules) Name() string {
+	return "secrets"
+}
+
+// Detect ищет секреты по правилам в тексте и ссылках статьи.
+// Совпадения с неверной контрольной суммой или низкой энтропией отбрасываются.
+func (r *SecretRules) Detect(page Page) []Finding {
+	var findings []Finding
+	text := page.Text + "\n" + strings.Join(page.Links, "\n")
+
+	for _, rule := range r.rules {
+		secretGroup := rule.re.SubexpIndex("secret")
+		for _, match := range rule.re.FindAllStringSubmatch(text, -1) {
+			secret := match[0]
+			if secretGroup > 0 {
+				secret = match[secretGroup]
+			}
+			if !rule.valid(secret) {
+				continue
+			}
+
+			finding := NewSecretFinding(r.Name(), rule.ID, page.URL, "", secret)
+			finding.Domain = rule.Owner
+			finding.Severity = rule.Severity
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// valid проверяет контрольную сумму и энтропию секрета
+func (rule *SecretRule) valid(secret string) bool {
+	if secret == "" {
+		return false
+	}
+	if rule.Checksum != "" && !checksumValidators[rule.Checksum](secret) {
+		return false
+	}
+	return shannonEntropy(secret) >= rule.Entropy
+}
+
+// shannonEntropy вычисляет энтропию строки в битах на символ
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// validLuhn проверяет контрольную цифру Луна у числового секрета
+func validLuhn(secret string) bool {
+	if len(secret) < 2 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(secret) - 1; i >= 0; i-- {
+		c := secret[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validCRC32Hex проверяет, что последние 8 символов - CRC32 остальной части в hex
+func validCRC32Hex(secret string) bool {
+	if len(secret) <= 8 {
+		return false
+	}
+	body, sum := secret[:len(secret)-8], secret[len(secret)-8:]
+	return strings.EqualFold(sum, fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(body))))
+}
+
+// base62Alphabet - алфавит base62 для контрольных сумм ключей
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// validCRC32Base62 проверяет, что последние 6 символов - CRC32 остальной части в base62
+// с дополнением нулями (формат токенов GitHub)
+func validCRC32Base62(secret string) bool {
+	if len(secret) <= 6 {
+		return false
+	}
+	body, sum := secret[:len(secret)-6], secret[len(secret)-6:]
+
+	value := crc32.ChecksumIEEE([]byte(body))
+	encoded := make([]byte, 6)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		encoded[i] = base62Alphabet[value%62]
+		value /= 62
+	}
+	return sum == string(encoded)
+}
@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// stixSpecVersion - версия STIX объектов экспорта
+const stixSpecVersion = "2.1"
+
+// stixNamespace - пространство имен UUIDv5 для детерминированных идентификаторов STIX
+// (определено спецификацией STIX 2.1 для наблюдаемых объектов)
+var stixNamespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+
+// stixTimeLayout - формат времени STIX (UTC с миллисекундами)
+const stixTimeLayout = "2006-01-02T15:04:05.000Z"
+
+// TLPMarkings - идентификаторы предопределенных отметок TLP в STIX 2.1
+var TLPMarkings = map[string]string{
+	"white": "marking-definition--613f2e26-407d-48c7-9eca-b8e91df99dc9",
+	"green": "marking-definition--34098fce-860f-48ae-8e50-ebd3cc5e41da",
+	"amber": "marking-definition--f88d31f6-486f-44da-b317-01333bde0b82",
+	"red":   "marking-definition--5e57c739-391a-4eb3-b6be-7d15ca92d5ed",
+}
+
+// STIXBundle - набор объектов STIX 2.1
+type STIXBundle struct {
+	Type    string       `json:"type"`
+	ID      string       `json:"id"`
+	Objects []STIXObject `json:"objects"`
+}
+
+// STIXObject - объект STIX 2.1: identity, url, grouping, indicator, observed-data или relationship.
+// Заполняются только свойства, которые есть у типа объекта.
+type STIXObject struct {
+	Type              string   `json:"type"`
+	SpecVersion       string   `json:"spec_version"`
+	ID                string   `json:"id"`
+	Created           string   `json:"created,omitempty"`
+	Modified          string   `json:"modified,omitempty"`
+	CreatedByRef      string   `json:"created_by_ref,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	Description       string   `json:"description,omitempty"`
+	IdentityClass     string   `json:"identity_class,omitempty"`
+	Value             string   `json:"value,omitempty"`
+	Context           string   `json:"context,omitempty"`
+	IndicatorTypes    []string `json:"indicator_types,omitempty"`
+	Pattern           string   `json:"pattern,omitempty"`
+	PatternType       string   `json:"pattern_type,omitempty"`
+	ValidFrom         string   `json:"valid_from,omitempty"`
+	FirstObserved     string   `json:"first_observed,omitempty"`
+	LastObserved      string   `json:"last_observed,omitempty"`
+	NumberObserved    int      `json:"number_observed,omitempty"`
+	RelationshipType  string   `json:"relationship_type,omitempty"`
+	SourceRef         string   `json:"source_ref,omitempty"`
+	TargetRef         string   `json:"target_ref,omitempty"`
+	ObjectRefs        []string `json:"object_refs,omitempty"`
+	Labels            []string `json:"labels,omitempty"`
+	ObjectMarkingRefs []string `json:"object_marking_refs,omitempty"`
+}
+
+// STIXOptions - параметры экспорта в STIX
+type STIXOptions struct {
+	Producer string    // Имя источника (identity)
+	TLP      string    // white, green, amber, red; пусто - без отметки
+	Now      time.Time // Время создания объектов
+}
+
+// NewSTIXBundle строит набор STIX 2.1 из результатов сканирования: страницы становятся объектами url,
+// кампании из нескольких зеркал - объектами grouping, находки - парами indicator и observed-data.
+// Находки попадают в набор только замаскированными; отпечатки исходных значений не экспортируются.
+// Идентификаторы детерминированы, повторный экспорт тех же данных дает те же объекты.
+func NewSTIXBundle(articles []Article, campaigns []Campaign, findings []Finding, opts STIXOptions) (STIXBundle, error) {
+	var marking []string
+	if opts.TLP != "" {
+		id, ok := TLPMarkings[opts.TLP]
+		if !ok {
+			return STIXBundle{}, fmt.Errorf("неизвестная отметка TLP %q", opts.TLP)
+		}
+		marking = []string{id}
+	}
+
+	now := opts.Now.UTC().Format(stixTimeLayout)
+	producer := opts.Producer
+	if producer == "" {
+		producer = "telegraph-finder-go"
+	}
+
+	identity := STIXObject{
+		Type:          "identity",
+		SpecVersion:   stixSpecVersion,
+		ID:            stixID("identity", "producer", producer),
+		Created:       now,
+		Modified:      now,
+		Name:          producer,
+		IdentityClass: "system",
+	}
+	bundle := STIXBundle{Type: "bundle", Objects: []STIXObject{identity}}
+
+	// sdo заполняет общие свойства объектов домена
+	sdo := func(object STIXObject) STIXObject {
+		object.SpecVersion = stixSpecVersion
+		object.Created = now
+		object.Modified = now
+		object.CreatedByRef = identity.ID
+		object.ObjectMarkingRefs = marking
+		return object
+	}
+
+	// Страницы-источники: статьи и страницы находок, без повторов. Ссылки на страницы утечек
+	// помечаются той же отметкой TLP, что и объекты домена.
+	urls := make(map[string]string)
+	published := make(map[string]string)
+	addURL := func(rawURL string) string {
+		if id, ok := urls[rawURL]; ok {
+			return id
+		}
+		id := stixID("url", "value", rawURL)
+		urls[rawURL] = id
+		bundle.Objects = append(bundle.Objects, STIXObject{Type: "url", SpecVersion: stixSpecVersion, ID: id, Value: rawURL, ObjectMarkingRefs: marking})
+		return id
+	}
+	for _, article := range SortArticles(articles) {
+		addURL(article.URL)
+		if article.PublishedAt != nil {
+			published[article.URL] = article.PublishedAt.UTC().Format(stixTimeLayout)
+		}
+	}
+
+	for _, campaign := range campaigns {
+		if len(campaign.Articles) < 2 {
+			continue
+		}
+		refs := make([]string, 0, len(campaign.Articles))
+		for _, article := range campaign.Articles {
+			refs = append(refs, addURL(article.URL))
+		}
+		bundle.Objects = append(bundle.Objects, sdo(STIXObject{
+			Type:        "grouping",
+			ID:          stixID("grouping", "campaign", campaign.ID),
+			Name:        fmt.Sprintf("Кампания %.12s: %s", campaign.ID, campaign.Articles[0].Title),
+			Description: fmt.Sprintf("Перепубликации одного текста на Telegraph, зеркал: %d", len(campaign.Articles)),
+			Context:     "suspicious-activity",
+			ObjectRefs:  refs,
+		}))
+	}
+
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		// Идентификатор строится из замаскированного значения, а не из отпечатка секрета
+		key := strings.Join([]string{finding.Detector, finding.Type, finding.Owner, finding.Value, finding.Source}, "\x00")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		urlRef := addURL(finding.Source)
+
+		observedAt := now
+		if t, ok := published[finding.Source]; ok {
+			observedAt = t
+		}
+		labels := []string{finding.Detector, finding.Type}
+		if finding.Severity != "" {
+			labels = append(labels, "severity:"+finding.Severity)
+		}
+
+		observed := sdo(STIXObject{
+			Type:           "observed-data",
+			ID:             stixID("observed-data", "finding", key),
+			FirstObserved:  observedAt,
+			LastObserved:   observedAt,
+			NumberObserved: 1,
+			ObjectRefs:     []string{urlRef},
+			Labels:         labels,
+		})
+		indicator := sdo(STIXObject{
+			Type:           "indicator",
+			ID:             stixID("indicator", "finding", key),
+			Name:           fmt.Sprintf("Утечка данных %s: %s", finding.Owner, finding.Type),
+			Description:    fmt.Sprintf("Страница содержит данные домена %s (%s): %s", finding.Owner, finding.Type, finding.Value),
+			IndicatorTypes: []string{"compromised"},
+			Pattern:        fmt.Sprintf("[url:value = '%s']", stixPatternString(finding.Source)),
+			PatternType:    "stix",
+			ValidFrom:      observedAt,
+			Labels:         labels,
+		})
+		relationship := sdo(STIXObject{
+			Type:             "relationship",
+			ID:               stixID("relationship", "based-on", key),
+			RelationshipType: "based-on",
+			SourceRef:        indicator.ID,
+			TargetRef:        observed.ID,
+		})
+		bundle.Objects = append(bundle.Objects, observed, indicator, relationship)
+	}
+
+	bundle.ID = stixID("bundle", "objects", bundleKey(bundle.Objects))
+	return bundle, nil
+}
+
+// stixID возвращает идентификатор объекта STIX с UUIDv5 от свойства объекта в каноническом JSON
+func stixID(objectType, property, value string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(map[string]string{property: value})
+	return objectType + "--" + uuidV5(stixNamespace, strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// bundleKey собирает идентификаторы объектов набора для идентификатора самого набора
+func bundleKey(objects []STIXObject) string {
+	ids := make([]string, len(objects))
+	for i, object := range objects {
+		ids[i] = object.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+// uuidV5 вычисляет UUID версии 5 (SHA-1) имени в пространстве имен
+func uuidV5(namespace [16]byte, name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	sum := h.Sum(nil)
+
+	sum[6] = (sum[6] & 0x0f) | 0x50 // версия 5
+	sum[8] = (sum[8] & 0x3f) | 0x80 // вариант RFC 4122
+
+	b := hex.EncodeToString(sum[:16])
+	return b[0:8] + "-" + b[8:12] + "-" + b[12:16] + "-" + b[16:20] + "-" + b[20:32]
+}
+
+// stixPatternString экранирует строку для шаблона STIX
+func stixPatternString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
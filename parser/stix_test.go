@@ -0,0 +1,197 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// update перезаписывает эталонные файлы testdata: go test ./parser -run Golden -update
+var update = flag.Bool("update", false, "перезаписать эталонные файлы testdata")
+
+// stixIDPattern - формат идентификатора STIX 2.1: <тип>--<UUID>
+var stixIDPattern = regexp.MustCompile(`^([a-z0-9-]+)--[0-9a-f]{8}-[0-9a-f]{4}-[1-5][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// stixRequired - обязательные свойства объектов STIX 2.1 по типам (кроме type, id и spec_version)
+var stixRequired = map[string][]string{
+	"identity":      {"created", "modified", "name"},
+	"url":           {"value"},
+	"grouping":      {"created", "modified", "context", "object_refs"},
+	"indicator":     {"created", "modified", "pattern", "pattern_type", "valid_from"},
+	"observed-data": {"created", "modified", "first_observed", "last_observed", "number_observed", "object_refs"},
+	"relationship":  {"created", "modified", "relationship_type", "source_ref", "target_ref"},
+}
+
+// stixFixture возвращает статьи, кампанию из двух зеркал и находки для эталонного набора
+func stixFixture() ([]Article, []Campaign, []Finding) {
+	published := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	articles := []Article{
+		{Title: "Утечка", URL: "https://telegra.ph/leak-03-01", Month: 3, Day: 1, Index: 1, ContentHash: "aa", PublishedAt: &published},
+		{Title: "Утечка", URL: "https://telegra.ph/leak-03-01-2", Month: 3, Day: 1, Index: 2, ContentHash: "aa"},
+		{Title: "Другое", URL: "https://telegra.ph/other-03-02", Month: 3, Day: 2, Index: 1, ContentHash: "bb"},
+	}
+	campaigns := []Campaign{
+		{ID: "0123456789abcdef", Articles: articles[:2]},
+		{ID: "fedcba9876543210", Articles: articles[2:]},
+	}
+
+	email := NewSecretFinding("accounts", "email", articles[0].URL, "alice@example.com", "Hunter2Secret")
+	email.Owner = "example.com"
+	webhook := NewURLFinding("webhooks", "generic", articles[2].URL, "https://hooks.example.com/services/abc?token=s3cr3tt0ken")
+	webhook.Owner = "example.com"
+	secret := NewSecretFinding("secrets", "internal-token", articles[0].URL, "", "tok_live_0123456789")
+	secret.Owner = "example.com"
+	secret.Severity = SeverityCritical
+	// Повтор находки на той же странице не создает новых объектов
+	return articles, campaigns, []Finding{email, webhook, secret, email}
+}
+
+// TestSTIXBundleGolden сверяет набор STIX с эталоном и проверяет его форму по спецификации 2.1
+func TestSTIXBundleGolden(t *testing.T) {
+	articles, campaigns, findings := stixFixture()
+	opts := STIXOptions{Producer: "test-producer", TLP: "amber", Now: time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)}
+
+	bundle, err := NewSTIXBundle(articles, campaigns, findings, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "stix_bundle.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("эталон не найден (go test ./parser -run Golden -update): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("набор STIX отличается от эталона %s:\n%s", golden, got)
+	}
+
+	// Повторный экспорт тех же данных дает те же идентификаторы
+	again, err := NewSTIXBundle(articles, campaigns, findings, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != bundle.ID {
+		t.Errorf("идентификатор набора не детерминирован: %s и %s", bundle.ID, again.ID)
+	}
+
+	checkSTIXShape(t, got, TLPMarkings["amber"])
+
+	// Исходные секреты и их отпечатки в набор не попадают
+	for _, leaked := range []string{"Hunter2Secret", "s3cr3tt0ken", "tok_live_0123456789", findings[0].Fingerprint} {
+		if strings.Contains(string(got), leaked) {
+			t.Errorf("набор содержит %q", leaked)
+		}
+	}
+}
+
+// checkSTIXShape проверяет набор как JSON: обязательные свойства, формат идентификаторов,
+// ссылки только на объекты набора и отметку TLP на всех объектах, кроме источника
+func checkSTIXShape(t *testing.T, data []byte, marking string) {
+	t.Helper()
+
+	var bundle struct {
+		Type    string           `json:"type"`
+		ID      string           `json:"id"`
+		Objects []map[string]any `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Type != "bundle" || !strings.HasPrefix(bundle.ID, "bundle--") || !stixIDPattern.MatchString(bundle.ID) {
+		t.Errorf("некорректный набор: type=%q id=%q", bundle.Type, bundle.ID)
+	}
+
+	ids := make(map[string]bool)
+	counts := make(map[string]int)
+	for _, object := range bundle.Objects {
+		objectType, _ := object["type"].(string)
+		id, _ := object["id"].(string)
+		counts[objectType]++
+
+		if m := stixIDPattern.FindStringSubmatch(id); m == nil || m[1] != objectType {
+			t.Errorf("идентификатор %q не соответствует типу %q", id, objectType)
+		}
+		if ids[id] {
+			t.Errorf("повтор идентификатора %s", id)
+		}
+		ids[id] = true
+
+		if object["spec_version"] != "2.1" {
+			t.Errorf("%s: spec_version = %v", id, object["spec_version"])
+		}
+		required, known := stixRequired[objectType]
+		if !known {
+			t.Errorf("%s: неожиданный тип объекта", id)
+		}
+		for _, property := range required {
+			if _, ok := object[property]; !ok {
+				t.Errorf("%s: нет обязательного свойства %s", id, property)
+			}
+		}
+
+		refs, _ := object["object_marking_refs"].([]any)
+		if objectType != "identity" && (len(refs) != 1 || refs[0] != marking) {
+			t.Errorf("%s: отметка TLP %v, ожидалась %s", id, refs, marking)
+		}
+	}
+
+	for _, object := range bundle.Objects {
+		var refs []string
+		for _, property := range []string{"created_by_ref", "source_ref", "target_ref"} {
+			if ref, ok := object[property].(string); ok {
+				refs = append(refs, ref)
+			}
+		}
+		list, _ := object["object_refs"].([]any)
+		for _, ref := range list {
+			refs = append(refs, ref.(string))
+		}
+		for _, ref := range refs {
+			if !ids[ref] {
+				t.Errorf("%s: ссылка на отсутствующий объект %s", object["id"], ref)
+			}
+		}
+	}
+
+	// Три страницы, одна кампания из двух зеркал, три уникальные находки
+	want := map[string]int{"identity": 1, "url": 3, "grouping": 1, "indicator": 3, "observed-data": 3, "relationship": 3}
+	for objectType, n := range want {
+		if counts[objectType] != n {
+			t.Errorf("объектов %s: %d, ожидалось %d", objectType, counts[objectType], n)
+		}
+	}
+}
+
+// TestSTIXBundleWithoutTLP проверяет, что без отметки TLP объекты не ссылаются на определения отметок
+func TestSTIXBundleWithoutTLP(t *testing.T) {
+	articles, campaigns, findings := stixFixture()
+	bundle, err := NewSTIXBundle(articles, campaigns, findings, STIXOptions{Now: time.Unix(0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range bundle.Objects {
+		if len(object.ObjectMarkingRefs) > 0 {
+			t.Errorf("%s: неожиданная отметка %v", object.ID, object.ObjectMarkingRefs)
+		}
+	}
+
+	if _, err := NewSTIXBundle(articles, campaigns, findings, STIXOptions{TLP: "purple"}); err == nil {
+		t.Error("неизвестная отметка TLP принята")
+	}
+}
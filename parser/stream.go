@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"context"
+)
+
+// EventKind определяет тип события потокового поиска
+type EventKind int
+
+const (
+	EventArticle  EventKind = iota // Найдена статья
+	EventError                     // Ошибка при проверке ссылки
+	EventProgress                  // Обновление прогресса
+)
+
+// String возвращает название типа события
+func (k EventKind) String() string {
+	switch k {
+	case EventArticle:
+		return "article"
+	case EventError:
+		return "error"
+	case EventProgress:
+		return "progress"
+	default:
+		return "unknown"
+	}
+}
+
+// Event - событие потокового поиска
+type Event struct {
+	Kind     EventKind
+	URL      string   // Проверенная ссылка (для article и error)
+	Article  Article  // Найденная статья (для article)
+	Err      error    // Ошибка проверки ссылки (для error)
+	Progress Progress // Снимок прогресса (для progress)
+}
+
+// streamBufferSize - размер буфера канала событий
+const streamBufferSize = 64
+
+// Search запускает поиск статей и возвращает канал событий.
+// Канал закрывается после завершения поиска или отмены ctx.
+func (p *Parser) Search(ctx context.Context, query string) <-chan Event {
+	return p.SearchBatch(ctx, []BatchQuery{{Query: query}})
+}
+
+// SearchStream запускает поиск статей с конфигурацией config и возвращает канал событий.
+// Оставлена для совместимости: то же, что New(config).Search(ctx, query).
+func SearchStream(ctx context.Context, query string, config ParserConfig) <-chan Event {
+	return New(config).Search(ctx, query)
+}
+
+// searchQueries возвращает варианты запроса: исходный и транслитерированный
+func searchQueries(query string, config ParserConfig) []string {
+	queries := []string{query}
+
+	// Если включена опция транслитерации, добавляем транслитерированный вариант
+	if config.IncludeTranslitVariants {
+		translitQuery := Translit(query)
+		if translitQuery != query {
+			queries = append(queries, translitQuery)
+		}
+	}
+
+	return queries
+}
+
+// searchMonths возвращает месяцы для поиска (если не указаны - все 1-12)
+func searchMonths(config ParserConfig) []int {
+	if len(config.MonthsToSearch) > 0 {
+		return config.MonthsToSearch
+	}
+
+	months := make([]int, 12)
+	for i := 0; i < 12; i++ {
+		months[i] = i + 1
+	}
+	return months
+}
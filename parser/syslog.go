@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Параметры сообщений syslog
+const (
+	syslogFacility = 16 // local0
+	syslogAppName  = "telegraph-finder"
+	cefVendor      = "telegraph-finder-go"
+	cefProduct     = "telegraph-finder"
+	cefVersion     = "1.0"
+)
+
+// Уровни важности syslog (RFC 5424)
+const (
+	SyslogCritical = 2
+	SyslogError    = 3
+	SyslogWarning  = 4
+	SyslogNotice   = 5
+)
+
+// SyslogSink отправляет события в SIEM как сообщения syslog RFC 5424 с CEF в тексте сообщения.
+// По TCP сообщения разделяются префиксом длины (RFC 6587), по UDP - одно сообщение в датаграмме.
+// Отправляются только находки: статус удаления страниц не отслеживается, пока нет режима наблюдения.
+type SyslogSink struct {
+	network  string
+	conn     net.Conn
+	hostname string
+	procID   string
+
+	mu sync.Mutex
+}
+
+// DialSyslog подключается к приемнику syslog по udp или tcp
+func DialSyslog(network, addr string, timeout time.Duration) (*SyslogSink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("неизвестный протокол syslog %q, допустимо: udp, tcp", network)
+	}
+
+	conn, err := net.DialTimeout(network, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogSink{
+		network:  network,
+		conn:     conn,
+		hostname: hostname,
+		procID:   strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// SendFinding отправляет замаскированную находку
+func (s *SyslogSink) SendFinding(finding Finding) error {
+	return s.Send("finding", findingSyslogSeverity(finding), FormatFindingCEF(finding))
+}
+
+// Send отправляет сообщение с идентификатором msgID и важностью syslog
+func (s *SyslogSink) Send(msgID string, severity int, message string) error {
+	line := formatSyslog(syslogFacility*8+severity, time.Now(), s.hostname, s.procID, msgID, message)
+	if s.network == "tcp" {
+		line = strconv.Itoa(len(line)) + " " + line
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.conn.Write([]byte(line))
+	return err
+}
+
+// Close закрывает соединение с приемником
+func (s *SyslogSink) Close() error {
+	return s.conn.Close()
+}
+
+// formatSyslog собирает сообщение RFC 5424 без структурированных данных
+func formatSyslog(priority int, t time.Time, hostname, procID, msgID, message string) string {
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s - %s",
+		priority, t.UTC().Format("2006-01-02T15:04:05.000000Z"), hostname, syslogAppName, procID, msgID, message)
+}
+
+// FormatFindingCEF формирует событие CEF для находки. Значение уже замаскировано детектором,
+// идентификатор externalId - отпечаток с ключом парсера, одинаковый для повторной находки в разных запусках.
+func FormatFindingCEF(finding Finding) string {
+	name := fmt.Sprintf("Утечка данных %s: %s", finding.Owner, finding.Type)
+	extension := []string{
+		"request=" + cefExtension(finding.Source),
+		"dhost=" + cefExtension(finding.Owner),
+		"externalId=" + cefExtension(finding.Fingerprint),
+		"cat=" + cefExtension(finding.Detector),
+		"cs1Label=type cs1=" + cefExtension(finding.Type),
+		"cs2Label=value cs2=" + cefExtension(finding.Value),
+	}
+	if finding.Severity != "" {
+		extension = append(extension, "cs3Label=severity cs3="+cefExtension(finding.Severity))
+	}
+
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		cefHeader(cefVendor), cefHeader(cefProduct), cefHeader(cefVersion),
+		cefHeader(finding.Detector+":"+finding.Type), cefHeader(name),
+		findingCEFSeverity(finding), strings.Join(extension, " "))
+}
+
+// findingCEFSeverity возвращает важность CEF (0-10) по важности правила
+func findingCEFSeverity(finding Finding) int {
+	switch finding.Severity {
+	case SeverityCritical:
+		return 10
+	case SeverityHigh:
+		return 8
+	case SeverityLow:
+		return 3
+	}
+	return 5
+}
+
+// findingSyslogSeverity возвращает важность syslog по важности правила
+func findingSyslogSeverity(finding Finding) int {
+	switch finding.Severity {
+	case SeverityCritical:
+		return SyslogCritical
+	case SeverityHigh:
+		return SyslogError
+	case SeverityLow:
+		return SyslogNotice
+	}
+	return SyslogWarning
+}
+
+// cefHeader экранирует поле заголовка CEF
+func cefHeader(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ").Replace(s)
+}
+
+// cefExtension экранирует значение расширения CEF
+func cefExtension(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syslogHeaderPattern - заголовок RFC 5424: <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID -
+var syslogHeaderPattern = regexp.MustCompile(`^<(\d{1,3})>1 (\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}Z) (\S+) telegraph-finder (\d+) finding - (CEF:0\|.*)$`)
+
+// syslogFixture возвращает находки для отправки: критическую из правил секретов и учетную запись
+// со значением, которое требует экранирования в CEF
+func syslogFixture() []Finding {
+	secret := NewSecretFinding("secrets", "token|internal", "https://telegra.ph/leak-03-01", "", "tok_live_0123456789")
+	secret.Owner = "example.com"
+	secret.Severity = SeverityCritical
+
+	account := NewSecretFinding("accounts", "email", "https://telegra.ph/leak-03-01?a=b", "alice@example.com", "pass")
+	account.Owner = "example.com\nforged"
+	account.Value = `alice\admin=x|y` + "\nforged=1"
+	return []Finding{secret, account}
+}
+
+// checkSyslogMessage проверяет заголовок RFC 5424 и возвращает событие CEF из сообщения
+func checkSyslogMessage(t *testing.T, message string, wantPriority int) string {
+	t.Helper()
+	m := syslogHeaderPattern.FindStringSubmatch(message)
+	if m == nil {
+		t.Fatalf("сообщение не соответствует RFC 5424: %q", message)
+	}
+	if priority, _ := strconv.Atoi(m[1]); priority != wantPriority {
+		t.Errorf("PRI = %d, ожидался %d", priority, wantPriority)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, m[2]); err != nil {
+		t.Errorf("некорректное время %q: %v", m[2], err)
+	}
+	return m[5]
+}
+
+// checkFindingCEF проверяет событие CEF находок syslogFixture: экранирование заголовка и расширения
+// и отсутствие исходного секрета
+func checkFindingCEF(t *testing.T, event string, finding Finding) {
+	t.Helper()
+	if strings.ContainsAny(event, "\r\n") {
+		t.Errorf("событие CEF содержит перевод строки: %q", event)
+	}
+	if strings.Contains(event, "tok_live_0123456789") {
+		t.Errorf("событие CEF содержит секрет: %q", event)
+	}
+	if !strings.Contains(event, " externalId="+finding.Fingerprint+" ") {
+		t.Errorf("нет externalId с отпечатком находки: %q", event)
+	}
+
+	switch finding.Detector {
+	case "secrets":
+		// В заголовке экранируется вертикальная черта, важность critical - 10
+		if !strings.HasPrefix(event, `CEF:0|telegraph-finder-go|telegraph-finder|1.0|secrets:token\|internal|`) ||
+			!strings.Contains(event, "|10|request=") {
+			t.Errorf("некорректный заголовок CEF: %q", event)
+		}
+	case "accounts":
+		// В заголовке перевод строки заменяется пробелом, в расширении экранируются \, = и перевод строки
+		for _, want := range []string{
+			"|Утечка данных example.com forged: email|5|",
+			`request=https://telegra.ph/leak-03-01?a\=b `,
+			`dhost=example.com\nforged `,
+			`cs2=alice\\admin\=x|y\nforged\=1`,
+		} {
+			if !strings.Contains(event, want) {
+				t.Errorf("в событии CEF нет %q: %q", want, event)
+			}
+		}
+	}
+}
+
+// TestSyslogUDP проверяет отправку находок по UDP: одно сообщение RFC 5424 в датаграмме
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := DialSyslog("udp", conn.LocalAddr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	findings := syslogFixture()
+	for _, finding := range findings {
+		if err := sink.SendFinding(finding); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64<<10)
+	for _, finding := range findings {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event := checkSyslogMessage(t, string(buf[:n]), syslogFacility*8+findingSyslogSeverity(finding))
+		checkFindingCEF(t, event, finding)
+	}
+}
+
+// TestSyslogTCP проверяет отправку находок по TCP: сообщения с префиксом длины (octet counting, RFC 6587)
+func TestSyslogTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	sink, err := DialSyslog("tcp", listener.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := syslogFixture()
+	for _, finding := range findings {
+		if err := sink.SendFinding(finding); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sink.Close()
+
+	var data []byte
+	select {
+	case data = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("приемник не получил сообщения")
+	}
+
+	// Кадр: длина в байтах, пробел, сообщение; кадры идут подряд без разделителей
+	reader := bufio.NewReader(strings.NewReader(string(data)))
+	for i, finding := range findings {
+		lengthStr, err := reader.ReadString(' ')
+		if err != nil {
+			t.Fatalf("кадр %d: нет префикса длины: %v", i, err)
+		}
+		length, err := strconv.Atoi(strings.TrimSuffix(lengthStr, " "))
+		if err != nil || length <= 0 {
+			t.Fatalf("кадр %d: некорректный префикс длины %q", i, lengthStr)
+		}
+		message := make([]byte, length)
+		if _, err := io.ReadFull(reader, message); err != nil {
+			t.Fatalf("кадр %d: сообщение короче префикса %d: %v", i, length, err)
+		}
+		event := checkSyslogMessage(t, string(message), syslogFacility*8+findingSyslogSeverity(finding))
+		checkFindingCEF(t, event, finding)
+	}
+	if rest, _ := io.ReadAll(reader); len(rest) > 0 {
+		t.Errorf("лишние данные после кадров: %q", rest)
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent - User-Agent, по которому Telegraph может опознать запросы монитора
+const DefaultUserAgent = "telegraph-finder-go/1.0 (brand protection monitor; +https://github.com/03O3/telegraph-parser-telegram-bot)"
+
+// NewTransport создает HTTP транспорт с keep-alive, HTTP/2 и таймаутами из конфигурации.
+// Число соединений с одним хостом ограничено MaxConcurrentRequests: сканирование выполняет
+// не больше стольких проверок одновременно, поэтому запросы не ждут свободного соединения.
+func NewTransport(config ParserConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	conns := int(config.MaxConcurrentRequests)
+	if conns < 1 {
+		conns = 1
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          conns * 2,
+		MaxIdleConnsPerHost:   conns,
+		MaxConnsPerHost:       conns,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// userAgentTransport подставляет User-Agent в запросы без него
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip добавляет заголовок User-Agent и выполняет запрос
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// NewClient создает HTTP клиент парсера: настроенный транспорт, User-Agent, метрики, кеш
+// и запрет редиректов на другой хост
+func NewClient(config ParserConfig) *http.Client {
+	return newClient(config, NewTransport(config))
+}
+
+// newClient создает HTTP клиент парсера поверх транспорта base
+func newClient(config ParserConfig, base http.RoundTripper) *http.Client {
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	var transport http.RoundTripper = &userAgentTransport{base: base, userAgent: userAgent}
+	// Кеш стоит перед метриками, чтобы в них попадали только реальные запросы
+	transport = CacheTransport(InstrumentTransport(transport, config.Metrics), config.Cache, config.MaxBodySize)
+
+	return &http.Client{
+		Timeout:       config.RequestTimeout,
+		Transport:     transport,
+		CheckRedirect: sameHostRedirect,
+	}
+}
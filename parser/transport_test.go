@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// benchServer - TLS сервер Telegraph для бенчмарков: отвечает с задержкой и считает новые соединения
+type benchServer struct {
+	*httptest.Server
+	conns atomic.Int64
+}
+
+// newBenchServer запускает сервер, который отдает статью по первой ссылке дня 1 каждого месяца и 404 по остальным
+func newBenchServer(b *testing.B, latency time.Duration, http2 bool) *benchServer {
+	s := &benchServer{}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(latency)
+		if !strings.HasSuffix(r.URL.Path, "-01") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><head><title>Статья</title></head><body><article>"+
+			strings.Repeat("Текст статьи для проверки загрузки. ", 4)+"</article></body></html>")
+	}))
+	s.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			s.conns.Add(1)
+		}
+	}
+	// Клиенты закрывают лишние соединения во время рукопожатия, это не ошибка бенчмарка
+	s.Config.ErrorLog = log.New(io.Discard, "", 0)
+	s.EnableHTTP2 = http2
+	s.StartTLS()
+	b.Cleanup(s.Close)
+	return s
+}
+
+// redirect направляет соединения транспорта с telegra.ph на тестовый сервер
+func (s *benchServer) redirect(transport *http.Transport) *http.Transport {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, s.Listener.Addr().String())
+	}
+
+	// Сертификат тестового сервера выдан на example.com
+	tlsConfig := s.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	tlsConfig.ServerName = "example.com"
+	if s.EnableHTTP2 {
+		tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport
+}
+
+// defaultTransport возвращает транспорт с лимитами http.DefaultTransport, как у клиентов до общего транспорта
+func defaultTransport() *http.Transport {
+	return http.DefaultTransport.(*http.Transport).Clone()
+}
+
+// benchTransports - транспорты для сравнения: стандартные лимиты и общий настроенный транспорт
+var benchTransports = []struct {
+	name      string
+	transport func(config ParserConfig) *http.Transport
+}{
+	{"default", func(ParserConfig) *http.Transport { return defaultTransport() }},
+	{"shared", NewTransport},
+}
+
+// BenchmarkFetchPage загружает страницы из параллельных обработчиков, как анализ найденных статей.
+// conns/op показывает, сколько новых TLS соединений потребовалось на одну загрузку.
+func BenchmarkFetchPage(b *testing.B) {
+	for _, bt := range benchTransports {
+		b.Run(bt.name, func(b *testing.B) {
+			server := newBenchServer(b, time.Millisecond, false)
+			config := DefaultConfig()
+			config.MaxConcurrentRequests = 16
+			client := newClient(config, server.redirect(bt.transport(config)))
+
+			b.SetParallelism(int(config.MaxConcurrentRequests))
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, _, _, err := fetchPage(context.Background(), client, "https://telegra.ph/bench-01-01", 0); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.ReportMetric(float64(server.conns.Load())/float64(b.N), "conns/op")
+		})
+	}
+}
+
+// BenchmarkScan сканирует три месяца запроса с задержкой ответа 50 мс, как в реальном поиске.
+// Одновременных проверок не больше MaxConcurrentRequests, поэтому с общим транспортом запросы
+// не ждут свободного соединения; таймауты считаются ошибкой.
+func BenchmarkScan(b *testing.B) {
+	cases := []struct {
+		name      string
+		http2     bool
+		transport func(config ParserConfig) *http.Transport
+		strict    bool
+	}{
+		{"default", false, benchTransports[0].transport, false},
+		{"shared", false, NewTransport, true},
+		{"shared-http2", true, NewTransport, true},
+	}
+
+	for _, bc := range cases {
+		b.Run(bc.name, func(b *testing.B) {
+			server := newBenchServer(b, 50*time.Millisecond, bc.http2)
+			config := DefaultConfig()
+			config.MaxConcurrentRequests = 32
+			config.MonthsToSearch = []int{1, 2, 3}
+			config.IncludeTranslitVariants = false
+			config.RetryCount = 0
+			config.DelayBetweenRequests = 0
+			p := New(config, WithClient(newClient(config, server.redirect(bc.transport(config)))))
+
+			var errs, articles int
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for event := range p.Search(context.Background(), "bench") {
+					switch event.Kind {
+					case EventError:
+						errs++
+					case EventArticle:
+						articles++
+					}
+				}
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(errs)/float64(b.N), "errors/op")
+			b.ReportMetric(float64(server.conns.Load())/float64(b.N), "conns/op")
+			if articles != 3*b.N {
+				b.Errorf("найдено статей: %d, ожидалось %d", articles, 3*b.N)
+			}
+			if bc.strict && errs > 0 {
+				b.Errorf("ошибок при сканировании: %d", errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// Watchlist - список доменов, принадлежащих нам (сотрудники, сервисы, продукты)
+type Watchlist struct {
+	domains []string
+}
+
+// NewWatchlist создает список наблюдения из доменов
+func NewWatchlist(domains []string) *Watchlist {
+	w := &Watchlist{}
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			w.domains = append(w.domains, domain)
+		}
+	}
+	return w
+}
+
+// LoadWatchlist загружает домены из файла, по одному на строку.
+// Пустые строки и строки, начинающиеся с #, пропускаются.
+func LoadWatchlist(path string) (*Watchlist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var domains []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewWatchlist(domains), nil
+}
+
+// Empty сообщает, что список пуст
+func (w *Watchlist) Empty() bool {
+	return w == nil || len(w.domains) == 0
+}
+
+// Domains возвращает домены списка
+func (w *Watchlist) Domains() []string {
+	if w == nil {
+		return nil
+	}
+	return append([]string(nil), w.domains...)
+}
+
+// Contains проверяет, что домен входит в список (включая поддомены).
+// Возвращает домен из списка, которому соответствует проверяемый.
+func (w *Watchlist) Contains(domain string) (string, bool) {
+	if w == nil {
+		return "", false
+	}
+
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for _, owned := range w.domains {
+		if domain == owned || strings.HasSuffix(domain, "."+owned) {
+			return owned, true
+		}
+	}
+	return "", false
+}